@@ -0,0 +1,181 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// An Aggregator accumulates a sequence of string values into a single string value. Empty
+// values are treated as nulls and are ignored by all aggregators.
+type Aggregator interface {
+	Add(v string) error // Add a value to the aggregate.
+	Value() string      // Answer the aggregate of the values added so far.
+}
+
+// An AggregatorFactory constructs a new, empty Aggregator.
+type AggregatorFactory func() Aggregator
+
+// The names of the aggregations supported by NewAggregatorFactory.
+var AggregationNames = []string{"single", "first", "last", "count", "sum", "mean", "min", "max"}
+
+// Answer a factory for Aggregators of the named kind, which is one of:
+//
+//	single - the only value; it is an error for there to be more than one value
+//	first - the first value
+//	last - the last value
+//	count - the number of values
+//	sum - the sum of the numeric values
+//	mean - the mean of the numeric values
+//	min - the least value, according to LessDecimalStrings
+//	max - the greatest value, according to LessDecimalStrings
+//
+// Sums and means are calculated exactly, with Decimal arithmetic, so the sum has the largest scale
+// of its values and the mean has at least that scale.
+func NewAggregatorFactory(name string) (AggregatorFactory, error) {
+	switch name {
+	case "single":
+		return func() Aggregator { return &singleAggregator{} }, nil
+	case "first":
+		return func() Aggregator { return &firstAggregator{} }, nil
+	case "last":
+		return func() Aggregator { return &lastAggregator{} }, nil
+	case "count":
+		return func() Aggregator { return &countAggregator{} }, nil
+	case "sum":
+		return func() Aggregator { return &sumAggregator{} }, nil
+	case "mean":
+		return func() Aggregator { return &sumAggregator{mean: true} }, nil
+	case "min":
+		return func() Aggregator { return &extremeAggregator{} }, nil
+	case "max":
+		return func() Aggregator { return &extremeAggregator{max: true} }, nil
+	default:
+		return nil, fmt.Errorf("unknown aggregation: %s (expected one of: %s)", name, strings.Join(AggregationNames, ", "))
+	}
+}
+
+// Parse a string as a float64, ignoring surrounding white space.
+func parseFloat(v string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(v), 64)
+}
+
+// Format a float64 without an exponent and without superfluous digits.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+type singleAggregator struct {
+	value string
+}
+
+func (a *singleAggregator) Add(v string) error {
+	if v == "" {
+		return nil
+	}
+	if a.value != "" && a.value != v {
+		return fmt.Errorf("multiple values: %s and %s", a.value, v)
+	}
+	a.value = v
+	return nil
+}
+
+func (a *singleAggregator) Value() string {
+	return a.value
+}
+
+type firstAggregator struct {
+	value string
+}
+
+func (a *firstAggregator) Add(v string) error {
+	if a.value == "" {
+		a.value = v
+	}
+	return nil
+}
+
+func (a *firstAggregator) Value() string {
+	return a.value
+}
+
+type lastAggregator struct {
+	value string
+}
+
+func (a *lastAggregator) Add(v string) error {
+	if v != "" {
+		a.value = v
+	}
+	return nil
+}
+
+func (a *lastAggregator) Value() string {
+	return a.value
+}
+
+type countAggregator struct {
+	count int
+}
+
+func (a *countAggregator) Add(v string) error {
+	if v != "" {
+		a.count++
+	}
+	return nil
+}
+
+func (a *countAggregator) Value() string {
+	return strconv.Itoa(a.count)
+}
+
+type sumAggregator struct {
+	mean  bool
+	sum   *Decimal
+	count int
+}
+
+func (a *sumAggregator) Add(v string) error {
+	if v == "" {
+		return nil
+	}
+	if d, err := ParseDecimal(v); err != nil {
+		return fmt.Errorf("not a number: %s", v)
+	} else if a.sum == nil {
+		a.sum = d
+		a.count++
+	} else {
+		a.sum = a.sum.Add(d)
+		a.count++
+	}
+	return nil
+}
+
+func (a *sumAggregator) Value() string {
+	if a.count == 0 {
+		return ""
+	} else if a.mean {
+		return a.sum.Quo(int64(a.count)).String()
+	} else {
+		return a.sum.String()
+	}
+}
+
+type extremeAggregator struct {
+	max   bool
+	value string
+}
+
+func (a *extremeAggregator) Add(v string) error {
+	if v == "" {
+		return nil
+	}
+	if a.value == "" || (a.max && LessDecimalStrings(a.value, v)) || (!a.max && LessDecimalStrings(v, a.value)) {
+		a.value = v
+	}
+	return nil
+}
+
+func (a *extremeAggregator) Value() string {
+	return a.value
+}
@@ -0,0 +1,230 @@
+package csv
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// An AsOfDirection determines which right record is matched with each left record by an as-of join.
+type AsOfDirection int
+
+const (
+	AsOfBackward AsOfDirection = iota // the right record with the greatest ordering key less than or equal to the left's
+	AsOfForward                       // the right record with the least ordering key greater than or equal to the left's
+	AsOfNearest                       // the closer of the backward and forward matches, preferring the backward match
+)
+
+// AsOf specifies an as-of join. Rather than matching every pair of records with equal keys, an as-of
+// join matches each left record with at most one right record that has equal keys: the record whose
+// ordering key (RightKey) is closest to the ordering key of the left record (LeftKey) in the specified
+// Direction. If Tolerance is specified, a right record whose ordering key differs from the left's by more
+// than the tolerance does not match.
+//
+// The ordering keys are numbers or, if Format is specified, timestamps in the specified format (per
+// TimestampFormat). Tolerance is a number or, for timestamps, a duration per time.ParseDuration.
+//
+// Both streams must be sorted by the join keys and then by the ordering key. The streams are merged one
+// record at a time, so only the most recent right record is held in memory. Since each left record yields
+// at most one output record, RightOuter is ignored; unmatched left records are copied only if LeftOuter
+// is specified. If the prefixed ordering columns have the same name, only the left ordering column is
+// copied.
+type AsOf struct {
+	LeftKey   string        // the ordering column of the left stream
+	RightKey  string        // the ordering column of the right stream
+	Format    string        // the format of timestamp ordering keys. The ordering keys are numbers if empty.
+	Location  string        // the location in which timestamps are interpreted (per go time.LoadLocation())
+	Direction AsOfDirection // the direction in which a matching right record is sought
+	Tolerance string        // the greatest permitted difference between ordering keys. Unbounded if empty.
+}
+
+// Answer the greatest permitted distance between ordering keys, according to the specified ordering.
+func (a *AsOf) tolerance(order *ordering) (float64, error) {
+	if a.Tolerance == "" {
+		return math.Inf(1), nil
+	}
+	if t, err := order.parseDistance(a.Tolerance); err != nil {
+		return 0, fmt.Errorf("invalid tolerance: %v", err)
+	} else if t < 0 {
+		return 0, fmt.Errorf("invalid tolerance: %s is negative", a.Tolerance)
+	} else {
+		return t, nil
+	}
+}
+
+// Answer the name of the specified direction.
+func (d AsOfDirection) String() string {
+	switch d {
+	case AsOfForward:
+		return "forward"
+	case AsOfNearest:
+		return "nearest"
+	default:
+		return "backward"
+	}
+}
+
+// Answer the direction with the specified name.
+func ParseAsOfDirection(s string) (AsOfDirection, error) {
+	for _, d := range []AsOfDirection{AsOfBackward, AsOfForward, AsOfNearest} {
+		if d.String() == s {
+			return d, nil
+		}
+	}
+	return AsOfBackward, fmt.Errorf("unknown direction: %s (expected one of: backward, forward, nearest)", s)
+}
+
+func (p *Join) runAsOf(left Reader, right Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer left.Close()
+		defer right.Close()
+
+		a := p.AsOf
+
+		if _, x, _ := utils.Intersect(append(append([]string{}, p.LeftKeys...), a.LeftKey), left.Header()); len(x) != 0 {
+			return fmt.Errorf("left: %s does not exist in the data header", Format(x))
+		}
+		if _, x, _ := utils.Intersect(append(append([]string{}, p.RightKeys...), a.RightKey), right.Header()); len(x) != 0 {
+			return fmt.Errorf("right: %s does not exist in the data header", Format(x))
+		}
+
+		order, err := newOrdering(a.Format, a.Location)
+		if err != nil {
+			return err
+		}
+		tolerance, err := a.tolerance(order)
+		if err != nil {
+			return err
+		}
+
+		less := p.less()
+		compareKeys := compareWith(less)
+		leftKey := (&SortKeys{Keys: p.LeftKeys}).AsStringProjection()
+		rightKey := (&SortKeys{Keys: p.RightKeys}).AsStringProjection()
+
+		rightBlank := NewRecordBuilder(right.Header())([]string{})
+
+		keyHeader, leftHeader, rightHeader := p.headers(left.Header(), right.Header())
+		if p.LeftPrefix+a.LeftKey == p.RightPrefix+a.RightKey {
+			// the left ordering column takes precedence over the right ordering column of the same name
+			_, _, rightHeader = utils.Intersect([]string{a.RightKey}, rightHeader)
+		}
+		outputHeader := p.outputHeader(keyHeader, leftHeader, rightHeader)
+		if err = checkDuplicateColumns(outputHeader); err != nil {
+			return err
+		}
+		writer := builder(outputHeader)
+		defer writer.Close(err)
+		record := p.recordWriter(writer, keyHeader, leftHeader, rightHeader)
+
+		// prev is the last right record with the current left key whose ordering key does not exceed
+		// that of the current left record. next is the first right record that has not been consumed.
+		var prev, next Record
+		var prevOrder, nextOrder orderValue
+		var nextKey []string
+
+		advance := func() error {
+			r := <-right.C()
+			if r == nil {
+				next = nil
+				return nil
+			}
+			k := rightKey(r)
+			o, err := order.parse(r.Get(a.RightKey))
+			if err != nil {
+				return fmt.Errorf("right: %v", err)
+			}
+			if next != nil {
+				if c := compareKeys(k, nextKey); c < 0 || (c == 0 && order.compare(o, nextOrder) < 0) {
+					return fmt.Errorf("right: records are not sorted: %s follows %s", Format(r.AsSlice()), Format(next.AsSlice()))
+				}
+			}
+			next, nextKey, nextOrder = r, k, o
+			return nil
+		}
+
+		if err = advance(); err != nil {
+			return err
+		}
+
+		var last Record
+		var lastKey []string
+		var lastOrder orderValue
+		for l := range left.C() {
+			k := leftKey(l)
+			o, err := order.parse(l.Get(a.LeftKey))
+			if err != nil {
+				return fmt.Errorf("left: %v", err)
+			}
+			if last != nil {
+				if c := compareKeys(k, lastKey); c < 0 || (c == 0 && order.compare(o, lastOrder) < 0) {
+					return fmt.Errorf("left: records are not sorted: %s follows %s", Format(l.AsSlice()), Format(last.AsSlice()))
+				} else if c > 0 {
+					prev = nil
+				}
+			}
+			last, lastKey, lastOrder = l, k, o
+
+			for next != nil {
+				c := compareKeys(nextKey, k)
+				if c > 0 || (c == 0 && order.compare(nextOrder, o) > 0) {
+					break
+				}
+				if c == 0 {
+					prev, prevOrder = next, nextOrder
+				}
+				if err := advance(); err != nil {
+					return err
+				}
+			}
+
+			var backward, forward Record
+			var bd, fd float64
+			if prev != nil {
+				backward, bd = prev, order.distance(o, prevOrder)
+			}
+			if next != nil && compareKeys(nextKey, k) == 0 {
+				forward, fd = next, order.distance(o, nextOrder)
+			}
+
+			var match Record
+			var distance float64
+			switch a.Direction {
+			case AsOfForward:
+				if backward != nil && bd == 0 {
+					match, distance = backward, bd
+				} else {
+					match, distance = forward, fd
+				}
+			case AsOfNearest:
+				if backward != nil && (forward == nil || bd <= fd) {
+					match, distance = backward, bd
+				} else {
+					match, distance = forward, fd
+				}
+			default:
+				match, distance = backward, bd
+			}
+			if match != nil && distance > tolerance {
+				match = nil
+			}
+
+			if match == nil {
+				if !p.LeftOuter {
+					continue
+				}
+				match = rightBlank
+			}
+
+			if err := writer.Write(record(k, l, match)); err != nil {
+				return err
+			}
+		}
+
+		if err = left.Error(); err != nil {
+			return err
+		}
+		return right.Error()
+	}()
+}
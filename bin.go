@@ -0,0 +1,335 @@
+package csv
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// Given a header-prefixed input stream of CSV records, BinProcess assigns the value of a numeric or
+// timestamp column (Column) to a bin. Exactly one of the following specifies the bins:
+//
+//	Width - bins of fixed width, aligned to Origin (0 or the Unix epoch by default)
+//	Edges - bins between consecutive pairs of the specified, ascending, edges
+//	Quantiles - the specified number of bins, each containing approximately the same number of records
+//
+// Each bin is a half-open range [lower, upper), except that the last bin defined by Edges or Quantiles
+// also contains its upper edge. The values are numbers or, if Format is specified, timestamps in the
+// specified format (per TimestampFormat), in which case Width is a duration (per time.ParseDuration).
+//
+// By default, the output stream contains the input records with an additional column (BinColumn) that
+// contains the bin of each record in the form [lower,upper). The bin of an empty value, or of a value that
+// lies outside the specified Edges, is empty.
+//
+// If Histogram is true, the output stream instead contains one record for each bin, with the columns
+// lower, upper and count. Fixed width bins are generated from the least to the greatest bin that contains
+// a value, including empty bins in between. Values that lie outside the specified Edges are not counted.
+//
+// Quantile bins and histograms require the whole input stream to be read before any output is generated,
+// so in those cases the records (quantile bins) or the counts (histograms) are held in memory.
+type BinProcess struct {
+	Column    string   // the column whose values are binned
+	BinColumn string   // the name of the additional column that contains the bin. "bin" if empty.
+	Width     string   // the width of fixed width bins: a number or, for timestamps, a duration
+	Origin    string   // the value at which fixed width bins are aligned
+	Edges     []string // the ascending edges of the bins
+	Quantiles int      // the number of quantile bins
+	Format    string   // the format of timestamp values. The values are numbers if empty.
+	Location  string   // the location in which timestamps are interpreted (per go time.LoadLocation())
+	Histogram bool     // write the count of each bin rather than the binned records
+}
+
+// The header of the histogram generated by a BinProcess.
+var HistogramHeader = []string{"lower", "upper", "count"}
+
+// Assigns values to bins.
+type binner struct {
+	order  *ordering
+	edges  []orderValue // the edges of explicit or quantile bins
+	width  float64      // the width of fixed bins, in nanoseconds for timestamps
+	origin orderValue   // the alignment of fixed bins
+}
+
+// Answer the bin that contains the specified value. For fixed bins, the bin number is an index relative
+// to the origin. For explicit bins, it is an index into the edges. The result is false if the value lies
+// outside the edges.
+func (b *binner) bin(v orderValue) (int64, bool) {
+	if b.edges == nil {
+		if b.order.format != nil {
+			return floorDiv(v.t.Sub(b.origin.t).Nanoseconds(), int64(b.width)), true
+		}
+		q := (v.f - b.origin.f) / b.width
+		if r := math.Round(q); math.Abs(q-r) < 1e-9 {
+			// a value on an edge may be represented just below it
+			q = r
+		}
+		return int64(math.Floor(q)), true
+	}
+	n := len(b.edges)
+	i := sort.Search(n, func(i int) bool {
+		return b.order.compare(b.edges[i], v) > 0
+	}) - 1
+	if i == n-1 && b.order.compare(v, b.edges[n-1]) == 0 && n > 1 {
+		// the last bin contains its upper edge
+		i = n - 2
+	}
+	if i < 0 || i >= n-1 {
+		return 0, false
+	}
+	return int64(i), true
+}
+
+// Answer the lower and upper bounds of the specified bin.
+func (b *binner) bounds(i int64) (orderValue, orderValue) {
+	if b.edges != nil {
+		return b.edges[i], b.edges[i+1]
+	}
+	if b.order.format != nil {
+		lower := b.origin.t.Add(time.Duration(i * int64(b.width)))
+		return orderValue{t: lower}, orderValue{t: lower.Add(time.Duration(b.width))}
+	}
+	return orderValue{f: roundFloat(b.origin.f + float64(i)*b.width)}, orderValue{f: roundFloat(b.origin.f + float64(i+1)*b.width)}
+}
+
+// Format the specified value.
+func (b *binner) format(v orderValue) string {
+	if b.order.format != nil {
+		return b.order.format.Format(v.t)
+	}
+	return formatFloat(v.f)
+}
+
+// Answer the label of the specified bin.
+func (b *binner) label(i int64) string {
+	lower, upper := b.bounds(i)
+	if b.edges != nil && int(i) == len(b.edges)-2 {
+		return "[" + b.format(lower) + "," + b.format(upper) + "]"
+	}
+	return "[" + b.format(lower) + "," + b.format(upper) + ")"
+}
+
+// Answer the floor of a/b.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// Round away the representation error accumulated by floating point arithmetic.
+func roundFloat(f float64) float64 {
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'g', 12, 64), 64)
+	return r
+}
+
+// Answer a binner for the receiver's fixed width or explicit bins.
+func (p *BinProcess) binner() (*binner, error) {
+	specified := 0
+	if p.Width != "" {
+		specified++
+	}
+	if len(p.Edges) > 0 {
+		specified++
+	}
+	if p.Quantiles > 0 {
+		specified++
+	}
+	if specified != 1 {
+		return nil, fmt.Errorf("exactly one of a width, edges or a number of quantiles must be specified")
+	}
+
+	order, err := newOrdering(p.Format, p.Location)
+	if err != nil {
+		return nil, err
+	}
+	b := &binner{order: order}
+
+	if p.Width != "" {
+		if b.width, err = order.parseDistance(p.Width); err != nil {
+			return nil, fmt.Errorf("invalid width: %v", err)
+		} else if b.width <= 0 {
+			return nil, fmt.Errorf("invalid width: %s is not positive", p.Width)
+		}
+		if p.Origin != "" {
+			if b.origin, err = order.parse(p.Origin); err != nil {
+				return nil, fmt.Errorf("invalid origin: %v", err)
+			}
+		} else if order.format != nil {
+			b.origin = orderValue{t: time.Unix(0, 0)}
+		}
+	} else if len(p.Edges) > 0 {
+		if len(p.Edges) < 2 {
+			return nil, fmt.Errorf("at least 2 edges must be specified")
+		}
+		b.edges = make([]orderValue, len(p.Edges))
+		for i, e := range p.Edges {
+			if b.edges[i], err = order.parse(e); err != nil {
+				return nil, fmt.Errorf("invalid edge: %v", err)
+			}
+			if i > 0 && order.compare(b.edges[i-1], b.edges[i]) >= 0 {
+				return nil, fmt.Errorf("the edges must be in ascending order")
+			}
+		}
+	}
+	return b, nil
+}
+
+// Answer the edges of the specified number of bins that each contain approximately the same number of the
+// specified values, which must be sorted.
+func quantileEdges(order *ordering, sorted []orderValue, bins int) []orderValue {
+	if len(sorted) == 0 {
+		return []orderValue{}
+	}
+	edges := []orderValue{}
+	n := len(sorted) - 1
+	for i := 0; i <= bins; i++ {
+		e := sorted[i*n/bins]
+		if len(edges) == 0 || order.compare(edges[len(edges)-1], e) < 0 {
+			edges = append(edges, e)
+		}
+	}
+	if len(edges) == 1 {
+		// every value is the same, so there is a single bin that contains only that value
+		edges = append(edges, edges[0])
+	}
+	return edges
+}
+
+func (p *BinProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+		if !utils.NewIndex(dataHeader).Contains(p.Column) {
+			return fmt.Errorf("%s does not exist in the data header", p.Column)
+		}
+
+		binColumn := p.BinColumn
+		if binColumn == "" {
+			binColumn = "bin"
+		}
+		if !p.Histogram && utils.NewIndex(dataHeader).Contains(binColumn) {
+			return fmt.Errorf("%s already exists in data header", binColumn)
+		}
+
+		b, err := p.binner()
+		if err != nil {
+			return err
+		}
+
+		parse := func(r Record) (orderValue, bool, error) {
+			s := r.Get(p.Column)
+			if s == "" {
+				return orderValue{}, false, nil
+			}
+			v, err := b.order.parse(s)
+			if err != nil {
+				return v, false, fmt.Errorf("%s: %v", p.Column, err)
+			}
+			return v, true, nil
+		}
+
+		// quantile bins are derived from the whole input stream
+		var records []Record
+		var source <-chan Record = reader.C()
+		if p.Quantiles > 0 {
+			values := []orderValue{}
+			for data := range reader.C() {
+				records = append(records, data)
+				if v, ok, err := parse(data); err != nil {
+					return err
+				} else if ok {
+					values = append(values, v)
+				}
+			}
+			if err = reader.Error(); err != nil {
+				return err
+			}
+			sort.Slice(values, func(i, j int) bool {
+				return b.order.compare(values[i], values[j]) < 0
+			})
+			b.edges = quantileEdges(b.order, values, p.Quantiles)
+
+			ch := make(chan Record, len(records))
+			for _, r := range records {
+				ch <- r
+			}
+			close(ch)
+			source = ch
+		}
+
+		if p.Histogram {
+			counts := map[int64]int{}
+			first, last := int64(0), int64(-1)
+			if b.edges != nil {
+				last = int64(len(b.edges) - 2)
+			}
+			for data := range source {
+				v, ok, err := parse(data)
+				if err != nil {
+					return err
+				} else if !ok {
+					continue
+				}
+				if i, ok := b.bin(v); ok {
+					if b.edges == nil {
+						// fixed width bins span the range of the values
+						if last < first {
+							first, last = i, i
+						} else if i < first {
+							first = i
+						} else if i > last {
+							last = i
+						}
+					}
+					counts[i]++
+				}
+			}
+			if err = reader.Error(); err != nil {
+				return err
+			}
+
+			writer := builder(HistogramHeader)
+			defer writer.Close(err)
+			for i := first; i <= last; i++ {
+				lower, upper := b.bounds(i)
+				o := writer.Blank()
+				o.Put("lower", b.format(lower))
+				o.Put("upper", b.format(upper))
+				o.Put("count", strconv.Itoa(counts[i]))
+				if err = writer.Write(o); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		augmentedHeader := make([]string, len(dataHeader)+1)
+		copy(augmentedHeader, dataHeader)
+		augmentedHeader[len(dataHeader)] = binColumn
+
+		writer := builder(augmentedHeader)
+		defer writer.Close(err)
+
+		for data := range source {
+			augmentedData := writer.Blank()
+			augmentedData.PutAll(data)
+			if v, ok, err := parse(data); err != nil {
+				return err
+			} else if ok {
+				if i, ok := b.bin(v); ok {
+					augmentedData.Put(binColumn, b.label(i))
+				}
+			}
+			if err = writer.Write(augmentedData); err != nil {
+				return err
+			}
+		}
+		return reader.Error()
+	}()
+}
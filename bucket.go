@@ -0,0 +1,190 @@
+package csv
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// The hash algorithms that can be used by a BucketProcess.
+var bucketHashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"fnv":    func() hash.Hash { return fnv.New64a() },
+	"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+}
+
+// Given a header-prefixed input stream of CSV records and the specification of a key (Keys), assign
+// each record to one of a fixed number of buckets (Buckets) such that records with the same key are
+// always assigned to the same bucket.
+//
+// In the manner of SurrogateKeysProcess, the bucket is derived from a hash of the string representation
+// of a CSV record that contains the fields of the key. The first 8 bytes of the hash (4 bytes for crc32)
+// are interpreted as a big-endian unsigned integer and the bucket number is the remainder of that integer
+// after division by the number of buckets. The hash algorithm is one of md5 (the default), sha1, sha256,
+// fnv (64-bit FNV-1a) or crc32.
+//
+// When run as a process, the bucket number is written into an additional column (BucketColumn).
+// Alternatively, Router can be used to route each record to one of several writers.
+type BucketProcess struct {
+	Keys         []string // the columns of the key
+	Buckets      int      // the number of buckets
+	Hash         string   // the hash algorithm. md5 is used if empty.
+	BucketColumn string   // the name of the additional column that contains the bucket number
+}
+
+// Answer a function that calculates the bucket of a record.
+func (p *BucketProcess) bucketer() (func(r Record) int, error) {
+	name := p.Hash
+	if name == "" {
+		name = "md5"
+	}
+	newHash, ok := bucketHashes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash algorithm: %s", name)
+	}
+	if p.Buckets < 1 {
+		return nil, fmt.Errorf("the number of buckets must be at least 1")
+	}
+
+	h := newHash()
+	n := uint64(p.Buckets)
+	key := make([]string, len(p.Keys))
+	return func(r Record) int {
+		for i, k := range p.Keys {
+			key[i] = r.Get(k)
+		}
+		h.Reset()
+		h.Write([]byte(Format(key)))
+		sum := h.Sum(nil)
+		var v uint64
+		if len(sum) >= 8 {
+			v = binary.BigEndian.Uint64(sum)
+		} else {
+			v = uint64(binary.BigEndian.Uint32(sum))
+		}
+		return int(v % n)
+	}, nil
+}
+
+func (p *BucketProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		bucketColumn := p.BucketColumn
+
+		// create a stream from the header
+		dataHeader := reader.Header()
+
+		i, a, _ := utils.Intersect(p.Keys, dataHeader)
+		if len(a) > 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(a))
+		}
+
+		i, a, _ = utils.Intersect([]string{bucketColumn}, dataHeader)
+		if len(i) != 0 {
+			return fmt.Errorf("%s already exists in data header", i[0])
+		}
+
+		bucket, err := p.bucketer()
+		if err != nil {
+			return err
+		}
+
+		// create a new output stream
+		augmentedHeader := make([]string, len(dataHeader)+1)
+		copy(augmentedHeader, dataHeader)
+		augmentedHeader[len(dataHeader)] = bucketColumn
+
+		writer := builder(augmentedHeader)
+		defer writer.Close(err)
+		for data := range reader.C() {
+			augmentedData := writer.Blank()
+			augmentedData.PutAll(data)
+			augmentedData.Put(bucketColumn, strconv.Itoa(bucket(data)))
+			if err := writer.Write(augmentedData); err != nil {
+				return err
+			}
+		}
+		return reader.Error()
+	}()
+}
+
+// Answer a WriterBuilder whose Writers write each record into the writer, constructed by one of
+// the specified builders, that corresponds to the record's bucket. There must be exactly one
+// builder for each bucket.
+func (p *BucketProcess) Router(builders []WriterBuilder) WriterBuilder {
+	return func(header []string) Writer {
+		result := &bucketWriter{
+			header:  header,
+			builder: NewRecordBuilder(header),
+			writers: make([]Writer, len(builders)),
+		}
+		if len(builders) != p.Buckets {
+			result.err = fmt.Errorf("expected %d builders, found %d", p.Buckets, len(builders))
+		} else if _, x, _ := utils.Intersect(p.Keys, header); len(x) > 0 {
+			result.err = fmt.Errorf("%s does not exist in the data header", Format(x))
+		} else {
+			result.bucket, result.err = p.bucketer()
+		}
+		if result.err == nil {
+			for i, b := range builders {
+				result.writers[i] = b(header)
+			}
+		}
+		return result
+	}
+}
+
+type bucketWriter struct {
+	header  []string
+	builder RecordBuilder
+	writers []Writer
+	bucket  func(r Record) int
+	err     error
+}
+
+func (w *bucketWriter) Header() []string {
+	return w.header
+}
+
+func (w *bucketWriter) Blank() Record {
+	return w.builder(make([]string, len(w.header), len(w.header)))
+}
+
+func (w *bucketWriter) Write(r Record) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.writers[w.bucket(r)].Write(r)
+	return w.err
+}
+
+func (w *bucketWriter) Error() error {
+	return w.err
+}
+
+func (w *bucketWriter) Close(err error) error {
+	var result error
+	for _, b := range w.writers {
+		if b == nil {
+			continue
+		}
+		if e := b.Close(err); result == nil {
+			result = e
+		}
+	}
+	if w.err == nil {
+		w.err = err
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.BinProcess, *csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-bin", flag.ExitOnError)
+	var column string
+	var binColumn string
+	var width string
+	var origin string
+	var edges string
+	var quantiles int
+	var format string
+	var location string
+	var histogram bool
+
+	flags.StringVar(&column, "column", "", "The numeric or timestamp column whose values are binned.")
+	flags.StringVar(&binColumn, "bin-column", "bin", "The name of the additional column that contains the bin of each record.")
+	flags.StringVar(&width, "width", "", "The width of fixed width bins. A number or, for timestamps, a duration.")
+	flags.StringVar(&origin, "origin", "", "The value at which fixed width bins are aligned. Defaults to 0 or the Unix epoch.")
+	flags.StringVar(&edges, "edges", "", "The ascending edges of the bins.")
+	flags.IntVar(&quantiles, "quantiles", 0, "The number of bins, each containing approximately the same number of records.")
+	flags.StringVar(&format, "format", "", "The format of timestamp values. A go timestamp format or s|ms|ns. The values are numeric if not specified.")
+	flags.StringVar(&location, "location", "UTC", "The location in which timestamps are interpreted.")
+	flags.BoolVar(&histogram, "histogram", false, "Write the bounds and count of each bin rather than the binned records.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-bin {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	if column == "" {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--column must be specified.")
+	}
+
+	edgeList, err := csv.Parse(edges)
+	if err != nil && len(edges) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--edges must specify a list of edges.")
+	}
+
+	specified := 0
+	for _, b := range []bool{width != "", len(edgeList) > 0, quantiles > 0} {
+		if b {
+			specified++
+		}
+	}
+	if specified != 1 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("exactly one of --width, --edges or --quantiles must be specified.")
+	}
+
+	return &csv.BinProcess{
+		Column:    column,
+		BinColumn: binColumn,
+		Width:     width,
+		Origin:    origin,
+		Edges:     edgeList,
+		Quantiles: quantiles,
+		Format:    format,
+		Location:  location,
+		Histogram: histogram,
+	}, input, output, nil
+}
+
+func main() {
+	var p *csv.BinProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.BucketProcess, string, *csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-bucket", flag.ExitOnError)
+	var key string
+	var buckets int
+	var hash string
+	var column string
+	var template string
+
+	flags.StringVar(&key, "key", "", "The columns of the key used to assign records to buckets.")
+	flags.IntVar(&buckets, "buckets", 0, "The number of buckets.")
+	flags.StringVar(&hash, "hash", "md5", "The hash algorithm. One of: md5, sha1, sha256, fnv, crc32")
+	flags.StringVar(&column, "column", "", "The name of an additional column that contains the bucket number of each record.")
+	flags.StringVar(&template, "template", "", "Write the records of each bucket into a separate file, named by replacing {bucket} in the template with the bucket number.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, "", nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-bucket {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	// Use  a CSV parser to extract the partial keys from the parameter
+	keys, err := csv.Parse(key)
+	if err != nil || len(keys) < 1 {
+		usage()
+		return nil, "", nil, nil, fmt.Errorf("--key must specify one or more columns")
+	}
+
+	if buckets < 1 {
+		usage()
+		return nil, "", nil, nil, fmt.Errorf("--buckets must specify a positive number of buckets")
+	}
+
+	if (column == "") == (template == "") {
+		usage()
+		return nil, "", nil, nil, fmt.Errorf("exactly one of --column or --template must be specified")
+	}
+
+	if template != "" && !strings.Contains(template, "{bucket}") {
+		usage()
+		return nil, "", nil, nil, fmt.Errorf("--template must contain {bucket}")
+	}
+
+	return &csv.BucketProcess{
+		Keys:         keys,
+		Buckets:      buckets,
+		Hash:         hash,
+		BucketColumn: column,
+	}, template, input, output, nil
+}
+
+func main() {
+	var p *csv.BucketProcess
+	var template string
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var err error
+
+	err = func() error {
+		if p, template, input, output, err = configure(os.Args[1:]); err != nil {
+			return err
+		}
+
+		if reader, err = input.Open(); err != nil {
+			return err
+		}
+
+		errCh := make(chan error, 1)
+		if template == "" {
+			builder, err := output.Builder()
+			if err != nil {
+				return err
+			}
+			p.Run(reader, builder, errCh)
+		} else {
+			builders := make([]csv.WriterBuilder, p.Buckets)
+			for i := range builders {
+				o := &csv.Output{
+					File:      strings.Replace(template, "{bucket}", strconv.Itoa(i), -1),
+					Gzip:      output.Gzip,
+					GzipLevel: output.GzipLevel,
+				}
+				if builders[i], err = o.Builder(); err != nil {
+					return err
+				}
+			}
+			(&csv.CatProcess{}).Run(reader, p.Router(builders), errCh)
+		}
+		return <-errCh
+	}()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
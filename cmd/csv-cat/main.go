@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-cat", flag.ExitOnError)
+
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	return input, output, nil
+}
+
+func main() {
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				(&csv.CatProcess{}).Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.KeyCheckProcess, string, *csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-check-keys", flag.ExitOnError)
+	var key string
+	var foreignKey string
+	var parent string
+	var parentKey string
+
+	flags.StringVar(&key, "key", "", "The columns of the primary key, which must be non-empty and unique.")
+	flags.StringVar(&foreignKey, "foreign-key", "", "The columns of a foreign key, each value of which must exist in the --parent file.")
+	flags.StringVar(&parent, "parent", "", "The file referenced by the foreign key.")
+	flags.StringVar(&parentKey, "parent-key", "", "The columns of the --parent file referenced by the foreign key. Defaults to the columns of the foreign key.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, "", nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-check-keys {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	keys, err := csv.Parse(key)
+	if err != nil && len(key) > 0 {
+		usage()
+		return nil, "", nil, nil, fmt.Errorf("--key must specify the list of primary key columns.")
+	}
+
+	foreignKeys, err := csv.Parse(foreignKey)
+	if err != nil && len(foreignKey) > 0 {
+		usage()
+		return nil, "", nil, nil, fmt.Errorf("--foreign-key must specify the list of foreign key columns.")
+	}
+
+	parentKeys, err := csv.Parse(parentKey)
+	if err != nil && len(parentKey) > 0 {
+		usage()
+		return nil, "", nil, nil, fmt.Errorf("--parent-key must specify the list of parent key columns.")
+	} else if len(parentKey) == 0 {
+		parentKeys = foreignKeys
+	}
+
+	if (len(foreignKeys) > 0) != (parent != "") {
+		usage()
+		return nil, "", nil, nil, fmt.Errorf("--foreign-key and --parent must be specified together.")
+	}
+
+	if len(keys) == 0 && parent == "" {
+		usage()
+		return nil, "", nil, nil, fmt.Errorf("at least one of --key or --foreign-key must be specified.")
+	}
+
+	return &csv.KeyCheckProcess{
+		Keys:        keys,
+		ForeignKeys: foreignKeys,
+		ParentKeys:  parentKeys,
+		LineColumn:  input.LineColumn,
+	}, parent, input, output, nil
+}
+
+func main() {
+	var p *csv.KeyCheckProcess
+	var parent string
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, parent, input, output, err = configure(os.Args[1:]); err == nil {
+		if parent != "" {
+			p.Parent, err = (&csv.Input{Files: []string{parent}}).Open()
+		}
+		if err == nil {
+			if reader, err = input.Open(); err == nil {
+				if builder, err = output.Builder(); err == nil {
+					p.Run(reader, builder, errCh)
+					err = <-errCh
+				}
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
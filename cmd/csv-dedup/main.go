@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.DedupProcess, *csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-dedup", flag.ExitOnError)
+	var key string
+	var keep string
+	var maxColumn string
+	var mode string
+	var capacity int
+	var falsePositiveRate float64
+
+	flags.StringVar(&key, "key", "", "The columns of the key. Defaults to the entire record.")
+	flags.StringVar(&keep, "keep", "first", "Which record with each key is kept. One of: first, last, max")
+	flags.StringVar(&maxColumn, "max-column", "", "The numeric column whose greatest value determines the record kept by --keep max.")
+	flags.StringVar(&mode, "mode", "exact", "How duplicates are detected. One of: exact, sorted (input sorted by key), approximate (Bloom filter)")
+	flags.IntVar(&capacity, "capacity", csv.DefaultDedupCapacity, "The expected number of distinct keys (approximate mode only).")
+	flags.Float64Var(&falsePositiveRate, "false-positive-rate", csv.DefaultFalsePositiveRate, "The probability that a record with a distinct key is dropped (approximate mode only).")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-dedup {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	keys, err := csv.Parse(key)
+	if err != nil && len(key) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--key must specify the list of key columns.")
+	}
+
+	var policy csv.DedupPolicy
+	switch keep {
+	case "first":
+		policy = csv.KeepFirst
+	case "last":
+		policy = csv.KeepLast
+	case "max":
+		policy = csv.KeepMax
+		if maxColumn == "" {
+			usage()
+			return nil, nil, nil, fmt.Errorf("--keep max requires --max-column.")
+		}
+	default:
+		usage()
+		return nil, nil, nil, fmt.Errorf("unknown --keep policy: %s", keep)
+	}
+
+	var dedupMode csv.DedupMode
+	switch mode {
+	case "exact":
+		dedupMode = csv.DedupExact
+	case "sorted":
+		dedupMode = csv.DedupSorted
+	case "approximate":
+		dedupMode = csv.DedupApproximate
+	default:
+		usage()
+		return nil, nil, nil, fmt.Errorf("unknown --mode: %s", mode)
+	}
+
+	if capacity <= 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--capacity must be positive.")
+	}
+
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--false-positive-rate must be between 0 and 1.")
+	}
+
+	return &csv.DedupProcess{
+		Keys:              keys,
+		Policy:            policy,
+		MaxColumn:         maxColumn,
+		Mode:              dedupMode,
+		Capacity:          capacity,
+		FalsePositiveRate: falsePositiveRate,
+	}, input, output, nil
+}
+
+func main() {
+	var p *csv.DedupProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
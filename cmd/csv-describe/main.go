@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.ProfileProcess, *csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-describe", flag.ExitOnError)
+	var columns string
+	var approximate bool
+	var quantiles string
+	var top int
+
+	flags.StringVar(&columns, "columns", "", "The columns to profile. Defaults to every column.")
+	flags.BoolVar(&approximate, "approximate", false, "Estimate distinct counts and frequent values in bounded memory.")
+	flags.StringVar(&quantiles, "quantiles", "0.25,0.5,0.75", "The quantiles of numeric columns to estimate.")
+	flags.IntVar(&top, "top", csv.DefaultProfileTopN, "The number of frequent values to report.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-describe {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	profiled, err := csv.Parse(columns)
+	if err != nil && len(columns) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--columns must specify the list of columns to profile.")
+	}
+
+	qs := []float64{}
+	if len(quantiles) > 0 {
+		fields, err := csv.Parse(quantiles)
+		if err != nil {
+			usage()
+			return nil, nil, nil, fmt.Errorf("--quantiles must specify a list of numbers between 0 and 1.")
+		}
+		for _, f := range fields {
+			if q, err := strconv.ParseFloat(f, 64); err != nil || q < 0 || q > 1 {
+				usage()
+				return nil, nil, nil, fmt.Errorf("--quantiles must specify a list of numbers between 0 and 1.")
+			} else {
+				qs = append(qs, q)
+			}
+		}
+	}
+
+	if top < 1 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--top must be a positive number.")
+	}
+
+	return &csv.ProfileProcess{
+		Columns:     profiled,
+		Approximate: approximate,
+		Quantiles:   qs,
+		TopN:        top,
+	}, input, output, nil
+}
+
+func main() {
+	var p *csv.ProfileProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
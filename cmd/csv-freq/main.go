@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.FrequencyProcess, *csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-freq", flag.ExitOnError)
+	var key string
+	var top int
+	var countColumn string
+	var percentColumn string
+	var cumulativeColumn string
+	var approximate bool
+	var capacity int
+
+	flags.StringVar(&key, "key", "", "The columns whose values are counted. Defaults to the entire record.")
+	flags.IntVar(&top, "top", 0, "Write only the specified number of most frequent values.")
+	flags.StringVar(&countColumn, "count-column", "count", "The name of the count column.")
+	flags.StringVar(&percentColumn, "percent-column", "", "The name of an additional column that contains the percentage of records with each value.")
+	flags.StringVar(&cumulativeColumn, "cumulative-column", "", "The name of an additional column that contains the cumulative percentage.")
+	flags.BoolVar(&approximate, "approximate", false, "Count in bounded memory. The counts may be overestimated.")
+	flags.IntVar(&capacity, "capacity", csv.DefaultFrequencyCapacity, "The number of distinct values tracked at once (approximate mode only).")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-freq {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	keys, err := csv.Parse(key)
+	if err != nil && len(key) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--key must specify the list of columns to count.")
+	}
+
+	if top < 0 || capacity < 1 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--top must not be negative and --capacity must be positive.")
+	}
+
+	return &csv.FrequencyProcess{
+		Keys:             keys,
+		TopN:             top,
+		CountColumn:      countColumn,
+		PercentColumn:    percentColumn,
+		CumulativeColumn: cumulativeColumn,
+		Approximate:      approximate,
+		Capacity:         capacity,
+	}, input, output, nil
+}
+
+func main() {
+	var p *csv.FrequencyProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
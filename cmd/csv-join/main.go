@@ -5,22 +5,59 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/wildducktheories/go-csv"
 	"github.com/wildducktheories/go-csv/utils"
 )
 
-func configure(args []string) (*csv.Join, []string, error) {
+func configure(args []string) ([]*csv.Join, *csv.MultiJoin, []string, *csv.Output, error) {
 	flags := flag.NewFlagSet("csv-join", flag.ExitOnError)
 	var joinKey string
 	var numericKey string
+	var decimalKey string
+	var dateKey string
+	var location string
+	var compareKey string
 	var joinType string
+	var asOf string
+	var asOfDirection string
+	var asOfTolerance string
+	var asOfFormat string
+	var asOfLocation string
+	var prefix string
+	var interval string
+	var intervalFormat string
+	var intervalLocation string
+	var fuzzy string
+	var fuzzyMetric string
+	var fuzzyThreshold float64
+	var fuzzyScoreColumn string
 
-	flags.StringVar(&joinKey, "join-key", "", "The columns of the join key")
+	flags.StringVar(&joinKey, "join-key", "", "The columns of the join key. Each column may be of the form left=right or, to name the column in each file, a=b=c...")
 	flags.StringVar(&numericKey, "numeric", "", "The specified columns are treated as numeric strings.")
-	flags.StringVar(&joinType, "join-type", "outer", "The type of join to perform. One of: outer, left-outer, right-outer, inner")
+	flags.StringVar(&decimalKey, "decimal", "", "The specified columns are treated as exact decimal strings.")
+	flags.StringVar(&dateKey, "date", "", "The specified left hand side key columns, each of the form column=layout, are compared as timestamps. The layout, a go timestamp format or s|ms|ns, applies to the key in every file.")
+	flags.StringVar(&compareKey, "compare", "", "The specified columns, each of the form column=comparator, are compared with the named comparator. One of: "+strings.Join(csv.StringComparatorNames(), ", "))
+	flags.StringVar(&location, "location", "UTC", "The location in which timestamp join keys without a zone are interpreted.")
+	flags.StringVar(&joinType, "join-type", "outer", "The type of join to perform. One of: outer, left-outer, right-outer, inner. left-outer keeps every record of the first file and right-outer every record of the last file.")
+	flags.StringVar(&asOf, "as-of", "", "Perform an as-of join on the specified ordering columns, of the form left=right. The join key is optional.")
+	flags.StringVar(&asOfDirection, "as-of-direction", "backward", "The direction in which an as-of join seeks a matching right record. One of: backward, forward, nearest")
+	flags.StringVar(&asOfTolerance, "as-of-tolerance", "", "The greatest permitted difference between the ordering columns of an as-of join. A number or, for timestamps, a duration.")
+	flags.StringVar(&asOfFormat, "as-of-format", "", "The format of timestamp ordering columns. A go timestamp format or s|ms|ns. The ordering columns are numeric if not specified.")
+	flags.StringVar(&asOfLocation, "as-of-location", "UTC", "The location in which timestamp ordering columns are interpreted.")
+	flags.StringVar(&interval, "interval", "", "Perform an interval join of two files on the specified value,start,end columns: the value column of the left file must lie within [start, end) of the right file. The join key is optional.")
+	flags.StringVar(&intervalFormat, "interval-format", "", "The format of timestamp interval columns. A go timestamp format or s|ms|ns. The columns are numeric if not specified.")
+	flags.StringVar(&intervalLocation, "interval-location", "UTC", "The location in which timestamp interval columns are interpreted.")
+	flags.StringVar(&fuzzy, "fuzzy", "", "Perform a fuzzy join of two files on the specified text columns, of the form left=right. The join key, if any, is used for blocking.")
+	flags.StringVar(&fuzzyMetric, "fuzzy-metric", "levenshtein", "The similarity metric of a fuzzy join. One of: levenshtein, jaro-winkler")
+	flags.Float64Var(&fuzzyThreshold, "fuzzy-threshold", 0.8, "The least similarity, between 0 and 1, of records matched by a fuzzy join.")
+	flags.StringVar(&fuzzyScoreColumn, "fuzzy-score-column", "", "The name of an additional column that contains the similarity of records matched by a fuzzy join.")
+	flags.StringVar(&prefix, "prefix", "", "A list of prefixes, one for each file, for the names of the non-key columns of that file.")
+	output := &csv.Output{}
+	output.AddFlags(flags)
 	if err := flags.Parse(args); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
 	usage := func() {
@@ -30,37 +67,105 @@ func configure(args []string) (*csv.Join, []string, error) {
 
 	// Use  a CSV parser to extract the partial keys from the parameter
 	joinKeys, err := csv.Parse(joinKey)
-	if err != nil || len(joinKeys) < 1 {
+	special := asOf != "" || interval != "" || fuzzy != ""
+	if special && joinKey == "" {
+		joinKeys, err = []string{}, nil
+	}
+	if err != nil || (len(joinKeys) < 1 && !special) {
 		usage()
-		return nil, nil, fmt.Errorf("--join-key must specify one or more columns.")
+		return nil, nil, nil, nil, fmt.Errorf("--join-key must specify one or more columns.")
+	}
+	fn := flags.Args()
+	if len(fn) < 2 {
+		return nil, nil, nil, nil, fmt.Errorf("expected at least 2 file arguments, found %d", len(fn))
+	}
+
+	// the names of the key columns in each file
+	inputKeys := make([][]string, len(fn))
+	for i := range inputKeys {
+		inputKeys[i] = make([]string, len(joinKeys))
 	}
-	leftKeys := make([]string, len(joinKeys))
-	rightKeys := make([]string, len(joinKeys))
 	for i, k := range joinKeys {
 		split := strings.Split(k, "=")
-		if len(split) == 1 {
+		switch len(split) {
+		case 1:
 			split = append(split, split[0])
+		case 2, len(fn):
+		default:
+			return nil, nil, nil, nil, fmt.Errorf("each join key must be of the form left=right or name the column in each of the %d files", len(fn))
 		}
-		if len(split) != 2 {
-			return nil, nil, fmt.Errorf("each join key must be of the form left=right")
+		for f := range fn {
+			if f < len(split) {
+				inputKeys[f][i] = split[f]
+			} else {
+				inputKeys[f][i] = split[len(split)-1]
+			}
 		}
-		leftKeys[i] = split[0]
-		rightKeys[i] = split[1]
 	}
+	leftKeys := inputKeys[0]
+	rightKeys := inputKeys[1]
 
 	numeric, err := csv.Parse(numericKey)
 	if err != nil && len(numericKey) > 0 {
 		usage()
-		return nil, nil, fmt.Errorf("--numeric must specify the list of numeric keys.")
+		return nil, nil, nil, nil, fmt.Errorf("--numeric must specify the list of numeric keys.")
 	}
 
-	if i, _, _ := utils.Intersect(joinKeys, numeric); len(i) < len(numeric) {
-		return nil, nil, fmt.Errorf("--numeric must be a strict subset of left hand side --join-key")
+	if i, _, _ := utils.Intersect(leftKeys, numeric); len(i) < len(numeric) {
+		return nil, nil, nil, nil, fmt.Errorf("--numeric must be a strict subset of left hand side --join-key")
 	}
 
-	fn := flags.Args()
-	if len(fn) < 2 {
-		return nil, nil, fmt.Errorf("expected at least 2 file arguments, found %d", len(fn))
+	decimal, err := csv.Parse(decimalKey)
+	if err != nil && len(decimalKey) > 0 {
+		usage()
+		return nil, nil, nil, nil, fmt.Errorf("--decimal must specify the list of decimal keys.")
+	}
+
+	if i, _, _ := utils.Intersect(leftKeys, decimal); len(i) < len(decimal) {
+		return nil, nil, nil, nil, fmt.Errorf("--decimal must be a strict subset of left hand side --join-key")
+	}
+
+	dates, err := parsePairs("--date", dateKey, "column=layout")
+	if err != nil {
+		usage()
+		return nil, nil, nil, nil, err
+	}
+
+	for k := range dates {
+		if !utils.NewIndex(leftKeys).Contains(k) {
+			return nil, nil, nil, nil, fmt.Errorf("--date must be a strict subset of left hand side --join-key")
+		}
+	}
+
+	loc, err := time.LoadLocation(location)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	names, err := parsePairs("--compare", compareKey, "column=comparator")
+	if err != nil {
+		usage()
+		return nil, nil, nil, nil, err
+	}
+
+	comparators := map[string]csv.StringComparator{}
+	for k, n := range names {
+		if !utils.NewIndex(leftKeys).Contains(k) {
+			return nil, nil, nil, nil, fmt.Errorf("--compare must be a strict subset of left hand side --join-key")
+		}
+		if comparators[k], err = csv.LookupStringComparator(n); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+
+	prefixes, err := csv.Parse(prefix)
+	if err != nil && len(prefix) > 0 {
+		usage()
+		return nil, nil, nil, nil, fmt.Errorf("--prefix must specify a list of prefixes.")
+	} else if len(prefix) == 0 {
+		prefixes = make([]string, len(fn))
+	} else if len(prefixes) != len(fn) {
+		return nil, nil, nil, nil, fmt.Errorf("--prefix must specify one prefix for each of the %d files", len(fn))
 	}
 
 	var leftOuter, rightOuter bool
@@ -75,43 +180,208 @@ func configure(args []string) (*csv.Join, []string, error) {
 		rightOuter = true
 	}
 
-	return &csv.Join{
-		LeftKeys:   leftKeys,
-		RightKeys:  rightKeys,
-		Numeric:    numeric,
-		LeftOuter:  leftOuter,
-		RightOuter: rightOuter,
-	}, fn, nil
+	var asOfJoin *csv.AsOf
+	if asOf != "" {
+		split := strings.Split(asOf, "=")
+		if len(split) == 1 {
+			split = append(split, split[0])
+		}
+		if len(split) != 2 {
+			return nil, nil, nil, nil, fmt.Errorf("--as-of must be of the form left=right")
+		}
+		for _, k := range inputKeys[2:] {
+			if i, _, _ := utils.Intersect(rightKeys, k); len(i) != len(k) {
+				return nil, nil, nil, nil, fmt.Errorf("--as-of requires the files after the first to have the same key columns")
+			}
+		}
+		direction, err := csv.ParseAsOfDirection(asOfDirection)
+		if err != nil {
+			usage()
+			return nil, nil, nil, nil, err
+		}
+		asOfJoin = &csv.AsOf{
+			LeftKey:   split[0],
+			RightKey:  split[1],
+			Format:    asOfFormat,
+			Location:  asOfLocation,
+			Direction: direction,
+			Tolerance: asOfTolerance,
+		}
+	}
+
+	var intervalJoin *csv.Interval
+	if interval != "" {
+		columns, err := csv.Parse(interval)
+		if err != nil || len(columns) != 3 {
+			usage()
+			return nil, nil, nil, nil, fmt.Errorf("--interval must specify the value, start and end columns")
+		}
+		if asOfJoin != nil {
+			return nil, nil, nil, nil, fmt.Errorf("--interval cannot be combined with --as-of")
+		}
+		if len(fn) != 2 {
+			return nil, nil, nil, nil, fmt.Errorf("--interval requires exactly 2 file arguments, found %d", len(fn))
+		}
+		intervalJoin = &csv.Interval{
+			LeftKey:    columns[0],
+			RightStart: columns[1],
+			RightEnd:   columns[2],
+			Format:     intervalFormat,
+			Location:   intervalLocation,
+		}
+	}
+
+	var fuzzyJoin *csv.Fuzzy
+	if fuzzy != "" {
+		split := strings.Split(fuzzy, "=")
+		if len(split) == 1 {
+			split = append(split, split[0])
+		}
+		if len(split) != 2 {
+			return nil, nil, nil, nil, fmt.Errorf("--fuzzy must be of the form left=right")
+		}
+		if asOfJoin != nil || intervalJoin != nil {
+			return nil, nil, nil, nil, fmt.Errorf("--fuzzy cannot be combined with --as-of or --interval")
+		}
+		if len(fn) != 2 {
+			return nil, nil, nil, nil, fmt.Errorf("--fuzzy requires exactly 2 file arguments, found %d", len(fn))
+		}
+		fuzzyJoin = &csv.Fuzzy{
+			LeftKey:     split[0],
+			RightKey:    split[1],
+			Metric:      fuzzyMetric,
+			Threshold:   fuzzyThreshold,
+			ScoreColumn: fuzzyScoreColumn,
+		}
+	}
+
+	join := &csv.Join{
+		LeftKeys:    leftKeys,
+		RightKeys:   rightKeys,
+		Numeric:     numeric,
+		Decimal:     decimal,
+		Dates:       dates,
+		Location:    loc,
+		Comparators: comparators,
+		LeftOuter:   leftOuter,
+		RightOuter:  rightOuter,
+		AsOf:        asOfJoin,
+		Interval:    intervalJoin,
+		Fuzzy:       fuzzyJoin,
+		LeftPrefix:  prefixes[0],
+		RightPrefix: prefixes[1],
+	}
+	if asOfJoin != nil || intervalJoin != nil || fuzzyJoin != nil {
+		// one join for each file after the first. The left stream of each join after the first is the
+		// output of the previous join, whose columns are already prefixed.
+		joins := make([]*csv.Join, len(fn)-1)
+		joins[0] = join
+		for i := 1; i < len(joins); i++ {
+			next := *join
+			next.LeftPrefix = ""
+			next.RightPrefix = prefixes[i+1]
+			if asOfJoin != nil {
+				next.AsOf = &csv.AsOf{}
+				*next.AsOf = *asOfJoin
+				next.AsOf.LeftKey = prefixes[0] + asOfJoin.LeftKey
+			}
+			joins[i] = &next
+		}
+		return joins, nil, fn, output, nil
+	}
+
+	// other joins are performed in a single pass over all the files
+	multi := &csv.MultiJoin{
+		Keys:        leftKeys,
+		Numeric:     numeric,
+		Decimal:     decimal,
+		Dates:       dates,
+		Location:    loc,
+		Comparators: comparators,
+		Inputs:      make([]csv.JoinInput, len(fn)),
+	}
+	for i := range fn {
+		multi.Inputs[i] = csv.JoinInput{
+			Keys:   inputKeys[i],
+			Outer:  (i == 0 && leftOuter) || (i == len(fn)-1 && rightOuter) || (leftOuter && rightOuter),
+			Prefix: prefixes[i],
+		}
+	}
+	return nil, multi, fn, output, nil
 }
 
-func openReader(n string) (csv.Reader, error) {
-	if n == "-" {
-		return csv.WithIoReader(os.Stdin), nil
-	} else {
-		if f, err := os.Open(n); err != nil {
-			return nil, err
-		} else {
-			return csv.WithIoReader(f), nil
+// Parse the value of the named option, a list of column=value pairs, into a map from column to value.
+func parsePairs(option string, list string, form string) (map[string]string, error) {
+	result := map[string]string{}
+	if list == "" {
+		return result, nil
+	}
+	pairs, err := csv.Parse(list)
+	if err != nil {
+		return nil, fmt.Errorf("%s must specify a list of %s pairs.", option, form)
+	}
+	for _, pair := range pairs {
+		split := strings.SplitN(pair, "=", 2)
+		if len(split) != 2 || split[0] == "" || split[1] == "" {
+			return nil, fmt.Errorf("each %s column must be of the form %s", option, form)
+		}
+		result[split[0]] = split[1]
+	}
+	return result, nil
+}
+
+// Append the specified ordering column, whose values have the specified format and location, to the
+// sort keys. Numbers and epoch timestamps are compared numerically and other timestamps as instants.
+func addOrderingKey(keys *csv.SortKeys, column string, format string, location string) error {
+	keys.Keys = append(keys.Keys, column)
+	switch format {
+	case "", "s", "ms", "ns":
+		keys.Numeric = append(keys.Numeric, column)
+	default:
+		f, err := csv.NewTimestampFormat(format, location)
+		if err != nil {
+			return err
 		}
+		if keys.Comparators == nil {
+			keys.Comparators = map[string]csv.StringComparator{}
+		}
+		keys.Comparators[column] = csv.LessTimestampStrings(f)
 	}
+	return nil
+}
+
+func openReader(n string) (csv.Reader, error) {
+	return (&csv.Input{Files: []string{n}}).Open()
 }
 
 func main() {
-	var j *csv.Join
+	var joins []*csv.Join
+	var multi *csv.MultiJoin
 	var err error
 	var fn []string
+	var output *csv.Output
+	var builder csv.WriterBuilder
 
 	err = func() error {
-		if j, fn, err = configure(os.Args[1:]); err == nil {
+		if joins, multi, fn, output, err = configure(os.Args[1:]); err == nil {
+
+			if multi != nil {
+				return runMultiJoin(multi, fn, output)
+			}
+			j := joins[0]
 
 			// construct a sort process for the left most file
 
-			leftSortProcess := (&csv.SortKeys{
-				Numeric: j.Numeric,
-				Keys:    j.LeftKeys,
-			}).AsSortProcess()
+			leftSortKeys := &csv.SortKeys{
+				Numeric:     j.Numeric,
+				Decimal:     j.Decimal,
+				Dates:       j.Dates,
+				Location:    j.Location,
+				Comparators: j.Comparators,
+				Keys:        j.LeftKeys,
+			}
 
-			// map the numeric key to the keyspace of the rightmost files
+			// map the numeric, decimal, date and compared keys to the keyspace of the rightmost files
 
 			l2r := map[string]string{}
 			for i, k := range j.LeftKeys {
@@ -123,12 +393,52 @@ func main() {
 				rightNumeric[i] = l2r[k]
 			}
 
+			rightDecimal := make([]string, len(j.Decimal))
+			for i, k := range j.Decimal {
+				rightDecimal[i] = l2r[k]
+			}
+
+			rightDates := map[string]string{}
+			for k, layout := range j.Dates {
+				rightDates[l2r[k]] = layout
+			}
+
+			rightComparators := map[string]csv.StringComparator{}
+			for k, less := range j.Comparators {
+				rightComparators[l2r[k]] = less
+			}
+
 			// create a sort process for the right most files.
 
-			rightSortProcess := (&csv.SortKeys{
-				Numeric: rightNumeric,
-				Keys:    j.RightKeys,
-			}).AsSortProcess()
+			rightSortKeys := &csv.SortKeys{
+				Numeric:     rightNumeric,
+				Decimal:     rightDecimal,
+				Dates:       rightDates,
+				Location:    j.Location,
+				Comparators: rightComparators,
+				Keys:        j.RightKeys,
+			}
+
+			// as-of and interval joins also require each stream to be sorted by its ordering column
+
+			if j.AsOf != nil {
+				if err = addOrderingKey(leftSortKeys, j.AsOf.LeftKey, j.AsOf.Format, j.AsOf.Location); err != nil {
+					return err
+				}
+				if err = addOrderingKey(rightSortKeys, j.AsOf.RightKey, j.AsOf.Format, j.AsOf.Location); err != nil {
+					return err
+				}
+			} else if j.Interval != nil {
+				if err = addOrderingKey(leftSortKeys, j.Interval.LeftKey, j.Interval.Format, j.Interval.Location); err != nil {
+					return err
+				}
+				if err = addOrderingKey(rightSortKeys, j.Interval.RightStart, j.Interval.Format, j.Interval.Location); err != nil {
+					return err
+				}
+			}
+
+			leftSortProcess := leftSortKeys.AsSortProcess()
+			rightSortProcess := rightSortKeys.AsSortProcess()
 
 			// open one reader for each file
 			readers := make([]csv.Reader, len(fn))
@@ -141,15 +451,19 @@ func main() {
 			// create one join process for each of the last n-1 readers
 			procs := make([]csv.Process, len(readers)-1)
 			for i, _ := range procs {
-				procs[i] = j.WithRight(csv.WithProcess(readers[i+1], rightSortProcess))
+				procs[i] = joins[i].WithRight(csv.WithProcess(readers[i+1], rightSortProcess))
 			}
 
 			// create a pipeline from the n-1 join processes
 			pipeline := csv.NewPipeLine(procs)
 
+			if builder, err = output.Builder(); err != nil {
+				return err
+			}
+
 			// run the join pipeline with the first reader
 			var errCh = make(chan error, 1)
-			pipeline.Run(csv.WithProcess(readers[0], leftSortProcess), csv.WithIoWriter(os.Stdout), errCh)
+			pipeline.Run(csv.WithProcess(readers[0], leftSortProcess), builder, errCh)
 			return <-errCh
 		} else {
 			return err
@@ -157,7 +471,57 @@ func main() {
 	}()
 
 	if err != nil {
-		fmt.Printf("fatal: %v\n", err)
+		if _, ok := err.(*csv.DuplicateColumnsError); ok {
+			fmt.Fprintf(os.Stderr, "fatal: %v (use --prefix to distinguish the columns of each file)\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		}
 		os.Exit(1)
 	}
 }
+
+// Sort each file by its key columns and join the sorted files in a single pass.
+func runMultiJoin(multi *csv.MultiJoin, fn []string, output *csv.Output) error {
+	numeric := utils.NewIndex(multi.Numeric)
+	decimal := utils.NewIndex(multi.Decimal)
+	for i, n := range fn {
+		in := &multi.Inputs[i]
+
+		// map the numeric, decimal, date and compared keys to the keyspace of the file
+		sortKeys := &csv.SortKeys{
+			Keys:        in.Keys,
+			Dates:       map[string]string{},
+			Location:    multi.Location,
+			Comparators: map[string]csv.StringComparator{},
+		}
+		for x, k := range multi.Keys {
+			if layout, ok := multi.Dates[k]; ok {
+				sortKeys.Dates[in.Keys[x]] = layout
+			}
+			if less, ok := multi.Comparators[k]; ok {
+				sortKeys.Comparators[in.Keys[x]] = less
+			}
+			if numeric.Contains(k) {
+				sortKeys.Numeric = append(sortKeys.Numeric, in.Keys[x])
+			}
+			if decimal.Contains(k) {
+				sortKeys.Decimal = append(sortKeys.Decimal, in.Keys[x])
+			}
+		}
+
+		if reader, err := openReader(n); err != nil {
+			return err
+		} else {
+			in.Reader = csv.WithProcess(reader, sortKeys.AsSortProcess())
+		}
+	}
+
+	builder, err := output.Builder()
+	if err != nil {
+		return err
+	}
+
+	var errCh = make(chan error, 1)
+	multi.Run(builder, errCh)
+	return <-errCh
+}
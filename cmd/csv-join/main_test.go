@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+type bufferCloser struct{ *bytes.Buffer }
+
+func (bufferCloser) Close() error { return nil }
+
+// Answer a reader of the specified CSV data, sorted by the specified keys.
+func sortedReader(data string, keys *csv.SortKeys) csv.Reader {
+	reader := csv.WithIoReader(ioutil.NopCloser(strings.NewReader(data)))
+	return csv.WithProcess(reader, keys.AsSortProcess())
+}
+
+func TestAsOfJoinSortsTimestampLayouts(t *testing.T) {
+	join := &csv.Join{
+		AsOf: &csv.AsOf{LeftKey: "t", RightKey: "t", Format: "02/01/2006 15:04", Location: "UTC"},
+	}
+
+	leftKeys := &csv.SortKeys{}
+	rightKeys := &csv.SortKeys{}
+	if err := addOrderingKey(leftKeys, join.AsOf.LeftKey, join.AsOf.Format, join.AsOf.Location); err != nil {
+		t.Fatal(err)
+	}
+	if err := addOrderingKey(rightKeys, join.AsOf.RightKey, join.AsOf.Format, join.AsOf.Location); err != nil {
+		t.Fatal(err)
+	}
+
+	left := sortedReader("t,x\n10/01/2015 00:00,b\n02/01/2015 00:00,a\n", leftKeys)
+	right := sortedReader("t,y\n05/01/2015 00:00,B\n31/12/2014 00:00,A\n", rightKeys)
+
+	var buf bytes.Buffer
+	errCh := make(chan error, 1)
+	join.WithRight(right).Run(left, csv.WithIoWriter(bufferCloser{&buf}), errCh)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "t,x,y\n02/01/2015 00:00,a,A\n10/01/2015 00:00,b,B\n"
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestIntervalJoinSortsTimestampLayouts(t *testing.T) {
+	join := &csv.Join{
+		Interval: &csv.Interval{LeftKey: "t", RightStart: "start", RightEnd: "end", Format: "02/01/2006", Location: "UTC"},
+	}
+
+	leftKeys := &csv.SortKeys{}
+	rightKeys := &csv.SortKeys{}
+	if err := addOrderingKey(leftKeys, join.Interval.LeftKey, join.Interval.Format, join.Interval.Location); err != nil {
+		t.Fatal(err)
+	}
+	if err := addOrderingKey(rightKeys, join.Interval.RightStart, join.Interval.Format, join.Interval.Location); err != nil {
+		t.Fatal(err)
+	}
+
+	left := sortedReader("t,x\n10/01/2015,b\n02/01/2015,a\n", leftKeys)
+	right := sortedReader("start,end,y\n05/01/2015,20/01/2015,B\n31/12/2014,05/01/2015,A\n", rightKeys)
+
+	var buf bytes.Buffer
+	errCh := make(chan error, 1)
+	join.WithRight(right).Run(left, csv.WithIoWriter(bufferCloser{&buf}), errCh)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "t,x,start,end,y\n02/01/2015,a,31/12/2014,05/01/2015,A\n10/01/2015,b,05/01/2015,20/01/2015,B\n"
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+// Run the specified join of the specified CSV data and answer the output and error.
+func runJoin(join *csv.Join, left string, right string) (string, error) {
+	var buf bytes.Buffer
+	errCh := make(chan error, 1)
+	join.WithRight(sortedReader(right, &csv.SortKeys{})).Run(sortedReader(left, &csv.SortKeys{}), csv.WithIoWriter(bufferCloser{&buf}), errCh)
+	return buf.String(), <-errCh
+}
+
+func TestAsOfJoinSharedColumns(t *testing.T) {
+	left := "t,name\n1,a\n5,b\n"
+	right := "t,name\n0,X\n4,Y\n"
+
+	join := &csv.Join{AsOf: &csv.AsOf{LeftKey: "t", RightKey: "t"}}
+	if output, err := runJoin(join, left, right); err == nil {
+		t.Fatalf("expected an error, got:\n%s", output)
+	} else if _, ok := err.(*csv.DuplicateColumnsError); !ok {
+		t.Fatalf("expected a DuplicateColumnsError, got: %v", err)
+	}
+
+	join = &csv.Join{AsOf: &csv.AsOf{LeftKey: "t", RightKey: "t"}, LeftPrefix: "l_", RightPrefix: "r_"}
+	output, err := runJoin(join, left, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "l_t,l_name,r_t,r_name\n1,a,0,X\n5,b,4,Y\n"
+	if output != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestIntervalJoinSharedColumns(t *testing.T) {
+	left := "t,name\n1,a\n2,b\n"
+	right := "s,e,name\n0,3,X\n"
+
+	join := &csv.Join{Interval: &csv.Interval{LeftKey: "t", RightStart: "s", RightEnd: "e"}}
+	if output, err := runJoin(join, left, right); err == nil {
+		t.Fatalf("expected an error, got:\n%s", output)
+	} else if _, ok := err.(*csv.DuplicateColumnsError); !ok {
+		t.Fatalf("expected a DuplicateColumnsError, got: %v", err)
+	}
+
+	join = &csv.Join{Interval: &csv.Interval{LeftKey: "t", RightStart: "s", RightEnd: "e"}, RightPrefix: "r_"}
+	output, err := runJoin(join, left, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "t,name,r_s,r_e,r_name\n1,a,0,3,X\n2,b,0,3,X\n"
+	if output != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.MeltProcess, *csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-melt", flag.ExitOnError)
+	var id string
+	var values string
+	var variableColumn string
+	var valueColumn string
+	var skipEmpty bool
+
+	flags.StringVar(&id, "id", "", "The columns that identify each input record.")
+	flags.StringVar(&values, "values", "", "The columns whose values are converted into records. Defaults to every column not specified by --id.")
+	flags.StringVar(&variableColumn, "variable-column", "variable", "The name of the output column that contains the name of each value column.")
+	flags.StringVar(&valueColumn, "value-column", "value", "The name of the output column that contains each value.")
+	flags.BoolVar(&skipEmpty, "skip-empty", false, "Do not generate records for empty values.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-melt {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	idKeys, err := csv.Parse(id)
+	if err != nil {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--id must specify the list of identifying columns.")
+	}
+
+	valueKeys, err := csv.Parse(values)
+	if err != nil && len(values) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--values must specify the list of value columns.")
+	}
+
+	return &csv.MeltProcess{
+		IdKeys:         idKeys,
+		ValueKeys:      valueKeys,
+		VariableColumn: variableColumn,
+		ValueColumn:    valueColumn,
+		SkipEmpty:      skipEmpty,
+	}, input, output, nil
+}
+
+func main() {
+	var p *csv.MeltProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
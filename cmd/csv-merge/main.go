@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wildducktheories/go-csv"
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+func configure(args []string) (*csv.MergeProcess, []string, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-merge", flag.ExitOnError)
+	var key string
+	var numericKey string
+	var decimalKey string
+	var reverseKey string
+	var dedup bool
+
+	flags.StringVar(&key, "key", "", "The columns by which each input stream is sorted.")
+	flags.StringVar(&numericKey, "numeric", "", "The specified columns are treated as numeric strings.")
+	flags.StringVar(&decimalKey, "decimal", "", "The specified columns are treated as exact decimal strings.")
+	flags.StringVar(&reverseKey, "reverse", "", "The specified columns are sorted in reverse order.")
+	flags.BoolVar(&dedup, "dedup", false, "Only copy the first record for each distinct key into the output stream.")
+	output := &csv.Output{}
+	output.AddFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+
+	usage := func() {
+		fmt.Printf("usage: csv-merge {options} file...\n")
+		flags.PrintDefaults()
+	}
+
+	// Use  a CSV parser to extract the partial keys from the parameter
+	keys, err := csv.Parse(key)
+	if err != nil || len(keys) < 1 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--key must specify one or more columns.")
+	}
+
+	numeric, err := csv.Parse(numericKey)
+	if err != nil && len(numericKey) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--numeric must specify the list of numeric keys.")
+	}
+
+	decimal, err := csv.Parse(decimalKey)
+	if err != nil && len(decimalKey) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--decimal must specify the list of decimal keys.")
+	}
+
+	reversed, err := csv.Parse(reverseKey)
+	if err != nil && len(reverseKey) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--reverse must specify the list of keys to be sorted in reverse order.")
+	}
+
+	if i, _, _ := utils.Intersect(keys, numeric); len(i) < len(numeric) {
+		return nil, nil, nil, fmt.Errorf("--numeric must be a strict subset of --key")
+	}
+
+	if i, _, _ := utils.Intersect(keys, decimal); len(i) < len(decimal) {
+		return nil, nil, nil, fmt.Errorf("--decimal must be a strict subset of --key")
+	}
+
+	if i, _, _ := utils.Intersect(keys, reversed); len(i) < len(reversed) {
+		return nil, nil, nil, fmt.Errorf("--reverse must be a strict subset of --key")
+	}
+
+	fn := flags.Args()
+	if len(fn) < 1 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("expected at least 1 file argument")
+	}
+
+	return &csv.MergeProcess{
+		SortKeys: csv.SortKeys{Keys: keys, Numeric: numeric, Decimal: decimal, Reversed: reversed},
+		Dedup:    dedup,
+	}, fn, output, nil
+}
+
+func openReader(n string) (csv.Reader, error) {
+	return (&csv.Input{Files: []string{n}}).Open()
+}
+
+func main() {
+	var p *csv.MergeProcess
+	var err error
+	var fn []string
+	var output *csv.Output
+	var builder csv.WriterBuilder
+
+	err = func() error {
+		if p, fn, output, err = configure(os.Args[1:]); err != nil {
+			return err
+		}
+
+		readers := make([]csv.Reader, len(fn))
+		for i, n := range fn {
+			if readers[i], err = openReader(n); err != nil {
+				return err
+			}
+		}
+
+		if builder, err = output.Builder(); err != nil {
+			return err
+		}
+
+		errCh := make(chan error, 1)
+		p.Run(readers, builder, errCh)
+		return <-errCh
+	}()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
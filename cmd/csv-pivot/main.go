@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.PivotProcess, *csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-pivot", flag.ExitOnError)
+	var id string
+	var variableColumn string
+	var valueColumn string
+	var columns string
+	var aggregation string
+
+	flags.StringVar(&id, "id", "", "The columns that identify each output record.")
+	flags.StringVar(&variableColumn, "variable-column", "variable", "The input column that contains the names of the output columns.")
+	flags.StringVar(&valueColumn, "value-column", "value", "The input column that contains the values of the output columns.")
+	flags.StringVar(&columns, "columns", "", "The output columns. Defaults to the distinct values of the variable column, in order of first appearance.")
+	flags.StringVar(&aggregation, "aggregation", "single", "How colliding values are combined. One of: "+strings.Join(csv.AggregationNames, ", "))
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-pivot {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	idKeys, err := csv.Parse(id)
+	if err != nil {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--id must specify the list of identifying columns.")
+	}
+
+	outputColumns, err := csv.Parse(columns)
+	if err != nil && len(columns) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--columns must specify the list of output columns.")
+	}
+
+	if _, err := csv.NewAggregatorFactory(aggregation); err != nil {
+		usage()
+		return nil, nil, nil, err
+	}
+
+	return &csv.PivotProcess{
+		IdKeys:         idKeys,
+		VariableColumn: variableColumn,
+		ValueColumn:    valueColumn,
+		Columns:        outputColumns,
+		Aggregation:    aggregation,
+	}, input, output, nil
+}
+
+func main() {
+	var p *csv.PivotProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.ResampleProcess, *csv.Input, *csv.Output, error) {
+	var timestamp string
+	var format string
+	var location string
+	var interval time.Duration
+	var tags string
+	var values string
+	var aggregation string
+	var fill string
+
+	flags := flag.NewFlagSet("csv-resample", flag.ExitOnError)
+
+	flags.StringVar(&timestamp, "timestamp", "timestamp", "The name of the CSV timestamp field.")
+	flags.StringVar(&format, "format", "2006-01-02 15:04:05", "The format of the CSV timestamp field. A go timestamp format or s|ms|ns.")
+	flags.StringVar(&location, "location", "UTC", "The location in which the timestamp should be interpreted.")
+	flags.DurationVar(&interval, "interval", 0, "The length of each interval, for example: 5m")
+	flags.StringVar(&tags, "tags", "", "The CSV columns that identify each group of records.")
+	flags.StringVar(&values, "values", "", "The CSV columns to be aggregated.")
+	flags.StringVar(&aggregation, "aggregation", "mean", "How the values of each interval are combined. One of: "+strings.Join(csv.AggregationNames, ", "))
+	flags.StringVar(&fill, "fill", "null", "How the values of empty intervals are filled. One of: null, previous, linear")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	if _, err := time.LoadLocation(location); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if timestamp == "" {
+		return nil, nil, nil, errors.New("--timestamp column must be specified")
+	}
+
+	if interval <= 0 {
+		return nil, nil, nil, errors.New("--interval must specify a positive duration")
+	}
+
+	if _, err := csv.NewAggregatorFactory(aggregation); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var policy csv.ResampleFill
+	switch fill {
+	case "null":
+		policy = csv.FillNull
+	case "previous":
+		policy = csv.FillPrevious
+	case "linear":
+		policy = csv.FillLinear
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown --fill: %s", fill)
+	}
+
+	if valuesSlice, err := csv.Parse(values); err != nil {
+		return nil, nil, nil, errors.New("--values must specify a set of values columns")
+	} else if tagsSlice, err := csv.Parse(tags); err != nil && len(tags) > 0 {
+		return nil, nil, nil, errors.New("--tags must specify a set of tag columns")
+	} else {
+		if len(valuesSlice) == 0 {
+			return nil, nil, nil, errors.New("at least one values column must be specified")
+		}
+		return &csv.ResampleProcess{
+			Timestamp:   timestamp,
+			Format:      format,
+			Location:    location,
+			Interval:    interval,
+			Tags:        tagsSlice,
+			Values:      valuesSlice,
+			Aggregation: aggregation,
+			Fill:        policy,
+		}, input, output, nil
+	}
+}
+
+func main() {
+	var p *csv.ResampleProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (csv.Process, *csv.Input, *csv.Output, error) {
+	flags := flag.NewFlagSet("csv-sample", flag.ExitOnError)
+	var head int
+	var tail int
+	var size int
+	var probability float64
+	var seed int64
+	var strata string
+
+	flags.IntVar(&head, "head", 0, "Copy the first N records.")
+	flags.IntVar(&tail, "tail", 0, "Copy the last N records.")
+	flags.IntVar(&size, "size", 0, "Copy a uniform random sample of N records (from each stratum, if --strata is specified).")
+	flags.Float64Var(&probability, "probability", 0, "Copy each record with the specified probability.")
+	flags.Int64Var(&seed, "seed", 0, "The seed of the random number generator used by --size and --probability.")
+	flags.StringVar(&strata, "strata", "", "The columns that determine the stratum of each record sampled by --size.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-sample {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	strataKeys, err := csv.Parse(strata)
+	if err != nil && len(strata) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--strata must specify the list of stratum columns.")
+	}
+
+	var p csv.Process
+	count := 0
+	if head > 0 {
+		p = &csv.HeadProcess{Count: head}
+		count++
+	}
+	if tail > 0 {
+		p = &csv.TailProcess{Count: tail}
+		count++
+	}
+	if size > 0 {
+		p = &csv.ReservoirSampleProcess{Size: size, Seed: seed, StrataKeys: strataKeys}
+		count++
+	}
+	if probability > 0 {
+		if probability > 1 {
+			usage()
+			return nil, nil, nil, fmt.Errorf("--probability must be between 0 and 1.")
+		}
+		p = &csv.BernoulliSampleProcess{Probability: probability, Seed: seed}
+		count++
+	}
+
+	if count != 1 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("exactly one of --head, --tail, --size or --probability must be specified.")
+	}
+
+	if len(strataKeys) > 0 && size <= 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--strata may only be used with --size.")
+	}
+
+	return p, input, output, nil
+}
+
+func main() {
+	var p csv.Process
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
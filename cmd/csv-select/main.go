@@ -4,23 +4,38 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/wildducktheories/go-csv"
 )
 
-func configure(args []string) (*csv.SelectProcess, error) {
+func configure(args []string) (*csv.SelectProcess, *csv.Input, *csv.Output, error) {
 	flags := flag.NewFlagSet("csv-select", flag.ExitOnError)
 	var key string
 	var permuteOnly bool
+	var exclude bool
+	var glob bool
+	var regex bool
+	var strict bool
 
-	flags.StringVar(&key, "key", "", "The fields to copy into the output stream")
+	flags.StringVar(&key, "key", "", "The fields to copy into the output stream. A field may be renamed with old=new.")
 	flags.BoolVar(&permuteOnly, "permute-only", false, "Preserve all the fields of the input, but put the specified keys first")
+	flags.BoolVar(&exclude, "exclude", false, "Copy all the fields of the input except the specified keys")
+	flags.BoolVar(&glob, "glob", false, "The keys are glob patterns that may match several fields")
+	flags.BoolVar(&regex, "regex", false, "The keys are regular expressions that may match several fields")
+	flags.BoolVar(&strict, "strict", false, "Fail if a key does not match any field of the input")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
 	if err := flags.Parse(args); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
+	input.Files = flags.Args()
 
 	usage := func() {
-		fmt.Printf("usage: csv-select {options}\n")
+		fmt.Printf("usage: csv-select {options} [file...]\n")
 		flags.PrintDefaults()
 	}
 
@@ -28,28 +43,63 @@ func configure(args []string) (*csv.SelectProcess, error) {
 	keys, err := csv.Parse(key)
 	if err != nil || len(keys) < 1 {
 		usage()
-		return nil, fmt.Errorf("--key must specify one or more columns")
+		return nil, nil, nil, fmt.Errorf("--key must specify one or more columns")
+	}
+
+	if glob && regex {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--glob and --regex are mutually exclusive")
+	}
+
+	match := csv.MatchExact
+	rename := map[string]string{}
+	if glob {
+		match = csv.MatchGlob
+	} else if regex {
+		match = csv.MatchRegexp
+	} else {
+		for i, k := range keys {
+			split := strings.Split(k, "=")
+			if len(split) == 2 {
+				keys[i] = split[0]
+				rename[split[0]] = split[1]
+			} else if len(split) != 1 {
+				return nil, nil, nil, fmt.Errorf("each renamed key must be of the form old=new")
+			}
+		}
 	}
 
 	return &csv.SelectProcess{
 		Keys:        keys,
 		PermuteOnly: permuteOnly,
-	}, nil
+		Exclude:     exclude,
+		Match:       match,
+		Rename:      rename,
+		Strict:      strict,
+	}, input, output, nil
 
 }
 
 func main() {
 	var p *csv.SelectProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
 	var err error
 	var errCh = make(chan error, 1)
 
-	if p, err = configure(os.Args[1:]); err == nil {
-		p.Run(csv.WithIoReader(os.Stdin), csv.WithIoWriter(os.Stdout), errCh)
-		err = <-errCh
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
 	}
 
 	if err != nil {
-		fmt.Printf("fatal: %v\n", err)
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -4,26 +4,50 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/wildducktheories/go-csv"
 	"github.com/wildducktheories/go-csv/utils"
 )
 
-func configure(args []string) (*csv.SortProcess, error) {
+func configure(args []string) (*csv.SortProcess, *csv.Input, *csv.Output, error) {
 	flags := flag.NewFlagSet("csv-sort", flag.ExitOnError)
 	var key string
 	var numericKey string
+	var decimalKey string
 	var reverseKey string
+	var dateKey string
+	var location string
+	var compareKey string
+	var nullTokens string
+	var nullsFirstKey string
+	var nullsLastKey string
+	var nonNumeric string
 
 	flags.StringVar(&key, "key", "", "The columns used to sort the input stream by.")
 	flags.StringVar(&numericKey, "numeric", "", "The specified columns are treated as numeric strings.")
+	flags.StringVar(&decimalKey, "decimal", "", "The specified columns are treated as exact decimal strings.")
 	flags.StringVar(&reverseKey, "reverse", "", "The specified columns are sorted in reverse order.")
+	flags.StringVar(&dateKey, "date", "", "The specified columns, each of the form column=layout, are compared as timestamps. A layout is a go timestamp format or s|ms|ns.")
+	flags.StringVar(&compareKey, "compare", "", "The specified columns, each of the form column=comparator, are compared with the named comparator. One of: "+strings.Join(csv.StringComparatorNames(), ", "))
+	flags.StringVar(&location, "location", "UTC", "The location in which timestamps without a zone are interpreted.")
+	flags.StringVar(&nullTokens, "null-tokens", "", "The values, in addition to empty values, that are treated as NULL, for example: NULL,NA")
+	flags.StringVar(&nullsFirstKey, "nulls-first", "", "The NULL values of the specified columns are sorted before all other values.")
+	flags.StringVar(&nullsLastKey, "nulls-last", "", "The NULL values of the specified columns are sorted after all other values.")
+	flags.StringVar(&nonNumeric, "non-numeric", "lexical", "How values of --numeric or --decimal columns that are not numbers are sorted. One of: lexical, error, first, last")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
 	if err := flags.Parse(args); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
+	input.Files = flags.Args()
 
 	usage := func() {
-		fmt.Printf("usage: csv-sort {options}\n")
+		fmt.Printf("usage: csv-sort {options} [file...]\n")
 		flags.PrintDefaults()
 	}
 
@@ -31,44 +55,163 @@ func configure(args []string) (*csv.SortProcess, error) {
 	keys, err := csv.Parse(key)
 	if err != nil || len(keys) < 1 {
 		usage()
-		return nil, fmt.Errorf("--key must specify one or more columns.")
+		return nil, nil, nil, fmt.Errorf("--key must specify one or more columns.")
 	}
 
 	numeric, err := csv.Parse(numericKey)
 	if err != nil && len(numericKey) > 0 {
 		usage()
-		return nil, fmt.Errorf("--numeric must specify the list of numeric keys.")
+		return nil, nil, nil, fmt.Errorf("--numeric must specify the list of numeric keys.")
+	}
+
+	decimal, err := csv.Parse(decimalKey)
+	if err != nil && len(decimalKey) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--decimal must specify the list of decimal keys.")
 	}
 
 	reversed, err := csv.Parse(reverseKey)
 	if err != nil && len(reversed) > 0 {
 		usage()
-		return nil, fmt.Errorf("--reverse must specify the list of keys to be sorted in reverse order.")
+		return nil, nil, nil, fmt.Errorf("--reverse must specify the list of keys to be sorted in reverse order.")
 	}
 
 	if i, _, _ := utils.Intersect(keys, numeric); len(i) < len(numeric) {
-		return nil, fmt.Errorf("--numeric must be a strict subset of --key")
+		return nil, nil, nil, fmt.Errorf("--numeric must be a strict subset of --key")
+	}
+
+	if i, _, _ := utils.Intersect(keys, decimal); len(i) < len(decimal) {
+		return nil, nil, nil, fmt.Errorf("--decimal must be a strict subset of --key")
 	}
 
 	if i, _, _ := utils.Intersect(keys, reversed); len(i) < len(reversed) {
-		return nil, fmt.Errorf("--reverse must be a strict subset of --key")
+		return nil, nil, nil, fmt.Errorf("--reverse must be a strict subset of --key")
+	}
+
+	dates, err := parsePairs("--date", dateKey, "column=layout")
+	if err != nil {
+		usage()
+		return nil, nil, nil, err
+	}
+
+	for k := range dates {
+		if !utils.NewIndex(keys).Contains(k) {
+			return nil, nil, nil, fmt.Errorf("--date must be a strict subset of --key")
+		}
+	}
+
+	loc, err := time.LoadLocation(location)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	names, err := parsePairs("--compare", compareKey, "column=comparator")
+	if err != nil {
+		usage()
+		return nil, nil, nil, err
+	}
+
+	comparators := map[string]csv.StringComparator{}
+	for k, n := range names {
+		if !utils.NewIndex(keys).Contains(k) {
+			return nil, nil, nil, fmt.Errorf("--compare must be a strict subset of --key")
+		}
+		if comparators[k], err = csv.LookupStringComparator(n); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	tokens, err := csv.Parse(nullTokens)
+	if err != nil && len(nullTokens) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--null-tokens must specify the list of NULL values.")
+	}
+
+	nullsFirst, err := csv.Parse(nullsFirstKey)
+	if err != nil && len(nullsFirstKey) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--nulls-first must specify the list of keys whose NULLs are sorted first.")
+	}
+
+	nullsLast, err := csv.Parse(nullsLastKey)
+	if err != nil && len(nullsLastKey) > 0 {
+		usage()
+		return nil, nil, nil, fmt.Errorf("--nulls-last must specify the list of keys whose NULLs are sorted last.")
+	}
+
+	if i, _, _ := utils.Intersect(keys, nullsFirst); len(i) < len(nullsFirst) {
+		return nil, nil, nil, fmt.Errorf("--nulls-first must be a strict subset of --key")
 	}
 
-	return (&csv.SortKeys{Keys: keys, Numeric: numeric, Reversed: reversed}).AsSortProcess(), nil
+	if i, _, _ := utils.Intersect(keys, nullsLast); len(i) < len(nullsLast) {
+		return nil, nil, nil, fmt.Errorf("--nulls-last must be a strict subset of --key")
+	}
+
+	if i, _, _ := utils.Intersect(nullsFirst, nullsLast); len(i) > 0 {
+		return nil, nil, nil, fmt.Errorf("%s cannot be specified by both --nulls-first and --nulls-last", csv.Format(i))
+	}
+
+	order, err := csv.ParseNonNumericOrder(nonNumeric)
+	if err != nil {
+		usage()
+		return nil, nil, nil, err
+	}
+
+	return (&csv.SortKeys{
+		Keys:        keys,
+		Numeric:     numeric,
+		Decimal:     decimal,
+		Reversed:    reversed,
+		Dates:       dates,
+		Location:    loc,
+		Comparators: comparators,
+		NullTokens:  tokens,
+		NullsFirst:  nullsFirst,
+		NullsLast:   nullsLast,
+		NonNumeric:  order,
+	}).AsSortProcess(), input, output, nil
+}
+
+// Parse the value of the named option, a list of column=value pairs, into a map from column to value.
+func parsePairs(option string, list string, form string) (map[string]string, error) {
+	result := map[string]string{}
+	if list == "" {
+		return result, nil
+	}
+	pairs, err := csv.Parse(list)
+	if err != nil {
+		return nil, fmt.Errorf("%s must specify a list of %s pairs.", option, form)
+	}
+	for _, pair := range pairs {
+		split := strings.SplitN(pair, "=", 2)
+		if len(split) != 2 || split[0] == "" || split[1] == "" {
+			return nil, fmt.Errorf("each %s column must be of the form %s", option, form)
+		}
+		result[split[0]] = split[1]
+	}
+	return result, nil
 }
 
 func main() {
 	var p *csv.SortProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
 	var err error
 	var errCh = make(chan error, 1)
 
-	if p, err = configure(os.Args[1:]); err == nil {
-		p.Run(csv.WithIoReader(os.Stdin), csv.WithIoWriter(os.Stdout), errCh)
-		err = <-errCh
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
 	}
 
 	if err != nil {
-		fmt.Printf("fatal: %v\n", err)
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		os.Exit(1)
 	}
 }
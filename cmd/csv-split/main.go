@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wildducktheories/go-csv"
+)
+
+func configure(args []string) (*csv.PartitionWriter, *csv.Input, error) {
+	flags := flag.NewFlagSet("csv-split", flag.ExitOnError)
+	var key string
+	var dir string
+	var template string
+	var maxOpen int
+	var maxRows int
+	var maxBytes int64
+
+	flags.StringVar(&key, "key", "", "The columns whose values determine the output file of each record.")
+	flags.StringVar(&dir, "dir", ".", "The directory in which the output files are created.")
+	flags.StringVar(&template, "template", "", "The name of each output file, relative to --dir. {column} is replaced by the value of the column and {chunk} by the chunk number. Defaults to a Hive-style column=value layout.")
+	flags.IntVar(&maxOpen, "max-open", 64, "The maximum number of output files that are open at once.")
+	flags.IntVar(&maxRows, "rows", 0, "Start a new chunk after the specified number of records.")
+	flags.Int64Var(&maxBytes, "bytes", 0, "Start a new chunk once a chunk reaches the specified number of bytes.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	input.Files = flags.Args()
+
+	usage := func() {
+		fmt.Printf("usage: csv-split {options} [file...]\n")
+		flags.PrintDefaults()
+	}
+
+	keys, err := csv.Parse(key)
+	if err != nil && len(key) > 0 {
+		usage()
+		return nil, nil, fmt.Errorf("--key must specify the list of partition columns.")
+	}
+
+	if len(keys) == 0 && maxRows <= 0 && maxBytes <= 0 {
+		usage()
+		return nil, nil, fmt.Errorf("at least one of --key, --rows or --bytes must be specified.")
+	}
+
+	return &csv.PartitionWriter{
+		Keys:     keys,
+		Dir:      dir,
+		Template: template,
+		MaxOpen:  maxOpen,
+		MaxRows:  maxRows,
+		MaxBytes: maxBytes,
+	}, input, nil
+}
+
+func main() {
+	var p *csv.PartitionWriter
+	var input *csv.Input
+	var reader csv.Reader
+	var err error
+	var errCh = make(chan error, 1)
+
+	if p, input, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			(&csv.CatProcess{}).Run(reader, p.Builder(), errCh)
+			err = <-errCh
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
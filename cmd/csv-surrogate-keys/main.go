@@ -8,7 +8,7 @@ import (
 	"os"
 )
 
-func configure(args []string) (*csv.SurrogateKeysProcess, error) {
+func configure(args []string) (*csv.SurrogateKeysProcess, *csv.Input, *csv.Output, error) {
 	var naturalKey, surrogateKey string
 	var err error
 
@@ -17,12 +17,18 @@ func configure(args []string) (*csv.SurrogateKeysProcess, error) {
 	flags.StringVar(&naturalKey, "natural-key", "", "The fields of the natural key")
 	flags.StringVar(&surrogateKey, "surrogate-key", "", "The field name for the surrogate key.")
 
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
 	if err = flags.Parse(args); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
+	input.Files = flags.Args()
 
 	usage := func() {
-		fmt.Printf("usage: surrogate-keys {options}\n")
+		fmt.Printf("usage: surrogate-keys {options} [file...]\n")
 		flag.PrintDefaults()
 	}
 
@@ -30,32 +36,40 @@ func configure(args []string) (*csv.SurrogateKeysProcess, error) {
 	naturalKeys, err := csv.Parse(naturalKey)
 	if err != nil || len(naturalKey) < 1 {
 		usage()
-		return nil, fmt.Errorf("--natural-key must specify one or more columns")
+		return nil, nil, nil, fmt.Errorf("--natural-key must specify one or more columns")
 	}
 
 	if surrogateKey == "" {
 		usage()
-		return nil, fmt.Errorf("--surrogate-key must specify the name of a new column")
+		return nil, nil, nil, fmt.Errorf("--surrogate-key must specify the name of a new column")
 	}
 
 	return &csv.SurrogateKeysProcess{
 		NaturalKeys:  naturalKeys,
 		SurrogateKey: surrogateKey,
-	}, nil
+	}, input, output, nil
 }
 
 func main() {
 	var p *csv.SurrogateKeysProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
 	var err error
 
 	errCh := make(chan error, 1)
-	if p, err = configure(os.Args[1:]); err == nil {
-		p.Run(csv.WithIoReader(os.Stdin), csv.WithIoWriter(os.Stdout), errCh)
-		err = <-errCh
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
 	}
 
 	if err != nil {
-		fmt.Printf("fatal: %v\n", err)
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		os.Exit(1)
 	}
 }
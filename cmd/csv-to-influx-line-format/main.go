@@ -4,13 +4,14 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/wildducktheories/go-csv"
 )
 
-func configure(args []string) (*csv.InfluxLineFormatProcess, error) {
+func configure(args []string) (*csv.InfluxLineFormatProcess, *csv.Input, *csv.Output, error) {
 	var measurement string
 	var timestamp string
 	var format string
@@ -26,32 +27,38 @@ func configure(args []string) (*csv.InfluxLineFormatProcess, error) {
 	flags.StringVar(&location, "location", "UTC", "The location in which the timestamp should be interpreted.")
 	flags.StringVar(&tags, "tags", "", "The CSV columns to be used as tags.")
 	flags.StringVar(&values, "values", "", "The CSV columns to be used as values.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
 	if err := flags.Parse(args); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
+	input.Files = flags.Args()
 
 	if _, err := time.LoadLocation(location); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	if timestamp == "" {
-		return nil, errors.New("--timestamp column must be specified")
+		return nil, nil, nil, errors.New("--timestamp column must be specified")
 	}
 
 	if measurement == "" {
-		return nil, errors.New("--measurement must be specified")
+		return nil, nil, nil, errors.New("--measurement must be specified")
 	}
 
 	if valuesSlice, err := csv.Parse(values); err != nil {
-		return nil, errors.New("--values must specify a set of values columns")
+		return nil, nil, nil, errors.New("--values must specify a set of values columns")
 	} else if tagsSlice, err := csv.Parse(tags); err != nil {
-		return nil, errors.New("--tags must specify a st of tag columns")
+		return nil, nil, nil, errors.New("--tags must specify a st of tag columns")
 	} else {
 		if len(valuesSlice) == 0 {
-			return nil, errors.New("at least one values column must be specified")
+			return nil, nil, nil, errors.New("at least one values column must be specified")
 		}
 		if len(tagsSlice) == 0 {
-			return nil, errors.New("at least one tag column must be specified")
+			return nil, nil, nil, errors.New("at least one tag column must be specified")
 		}
 		return &csv.InfluxLineFormatProcess{
 			Measurement: measurement,
@@ -60,21 +67,32 @@ func configure(args []string) (*csv.InfluxLineFormatProcess, error) {
 			Location:    location,
 			Tags:        tagsSlice,
 			Values:      valuesSlice,
-		}, nil
+		}, input, output, nil
 	}
 }
 
 func main() {
 	var p *csv.InfluxLineFormatProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var out io.WriteCloser
 	var err error
 
-	if p, err = configure(os.Args[1:]); err == nil {
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
 		errCh := make(chan error, 1)
-		p.Run(csv.WithIoReader(os.Stdin), os.Stdout, errCh)
-		err = <-errCh
+		if reader, err = input.Open(); err == nil {
+			if out, err = output.Open(); err == nil {
+				p.Run(reader, out, errCh)
+				err = <-errCh
+				if e := out.Close(); err == nil {
+					err = e
+				}
+			}
+		}
 	}
 	if err != nil {
-		fmt.Printf("fatal: %v\n", err)
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -4,38 +4,59 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/wildducktheories/go-csv"
 )
 
-func configure(args []string) (*csv.CsvToJsonProcess, error) {
+func configure(args []string) (*csv.CsvToJsonProcess, *csv.Input, *csv.Output, error) {
 	var baseObject string
 	var stringsOnly bool
+	var decimal bool
 	flags := flag.NewFlagSet("csv-to-json", flag.ExitOnError)
 
 	flags.BoolVar(&stringsOnly, "strings", false, "Don't attempt to convert strings to other JSON types.")
+	flags.BoolVar(&decimal, "decimal", false, "Copy numbers exactly, rather than converting them to floating point.")
 	flags.StringVar(&baseObject, "base-object-key", "", "Write the other columns into the base JSON object found in the specified column.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
 	if err := flags.Parse(args); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
+	input.Files = flags.Args()
 	return &csv.CsvToJsonProcess{
 		BaseObject:  baseObject,
 		StringsOnly: stringsOnly,
-	}, nil
+		Decimal:     decimal,
+	}, input, output, nil
 }
 
 func main() {
 	var p *csv.CsvToJsonProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var out io.WriteCloser
 	var err error
 
-	if p, err = configure(os.Args[1:]); err == nil {
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
 		errCh := make(chan error, 1)
-		p.Run(csv.WithIoReader(os.Stdin), json.NewEncoder(os.Stdout), errCh)
-		err = <-errCh
+		if reader, err = input.Open(); err == nil {
+			if out, err = output.Open(); err == nil {
+				p.Run(reader, json.NewEncoder(out), errCh)
+				err = <-errCh
+				if e := out.Close(); err == nil {
+					err = e
+				}
+			}
+		}
 	}
 	if err != nil {
-		fmt.Printf("fatal: %v\n", err)
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		os.Exit(1)
 	}
 }
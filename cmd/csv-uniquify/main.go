@@ -8,7 +8,7 @@ import (
 	"os"
 )
 
-func configure(args []string) (*csv.UniquifyProcess, error) {
+func configure(args []string) (*csv.UniquifyProcess, *csv.Input, *csv.Output, error) {
 	var partialKey, additionalKey string
 
 	flags := flag.NewFlagSet("uniquify", flag.ContinueOnError)
@@ -16,12 +16,18 @@ func configure(args []string) (*csv.UniquifyProcess, error) {
 	flags.StringVar(&partialKey, "partial-key", "", "The fields of the partial key.")
 	flags.StringVar(&additionalKey, "additional-key", "", "The field name for the additional key.")
 
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
 	if err := flags.Parse(args); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
+	input.Files = flags.Args()
 
 	usage := func() {
-		fmt.Printf("usage: uniqify {options}\n")
+		fmt.Printf("usage: uniqify {options} [file...]\n")
 		flag.PrintDefaults()
 	}
 
@@ -29,32 +35,40 @@ func configure(args []string) (*csv.UniquifyProcess, error) {
 	partialKeys, err := csv.Parse(partialKey)
 	if err != nil || len(partialKeys) < 1 {
 		usage()
-		return nil, fmt.Errorf("--partial-key must specify one or more columns")
+		return nil, nil, nil, fmt.Errorf("--partial-key must specify one or more columns")
 	}
 
 	if additionalKey == "" {
 		usage()
-		return nil, fmt.Errorf("--additional-key must specify the name of new column")
+		return nil, nil, nil, fmt.Errorf("--additional-key must specify the name of new column")
 	}
 
 	return &csv.UniquifyProcess{
 		PartialKeys:   partialKeys,
 		AdditionalKey: additionalKey,
-	}, nil
+	}, input, output, nil
 }
 
 func main() {
 	var p *csv.UniquifyProcess
+	var input *csv.Input
+	var output *csv.Output
+	var reader csv.Reader
+	var builder csv.WriterBuilder
 	var err error
 	var errCh = make(chan error, 1)
 
-	if p, err = configure(os.Args[1:]); err == nil {
-		p.Run(csv.WithIoReader(os.Stdin), csv.WithIoWriter(os.Stdout), errCh)
-		err = <-errCh
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
+		if reader, err = input.Open(); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				p.Run(reader, builder, errCh)
+				err = <-errCh
+			}
+		}
 	}
 
 	if err != nil {
-		fmt.Printf("fatal: %v\n", err)
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -1,7 +1,6 @@
 package main
 
 import (
-	encoding "encoding/csv"
 	"flag"
 	"fmt"
 	"os"
@@ -13,47 +12,53 @@ const (
 	TAB = rune(0x09)
 )
 
-func configure(args []string) (*csv.UseTabProcess, error) {
+func configure(args []string) (*csv.UseTabProcess, *csv.Input, *csv.Output, error) {
 	flags := flag.NewFlagSet("csv-use-tab", flag.ExitOnError)
 
 	var onRead bool
 
 	flags.BoolVar(&onRead, "on-read", false, "Whether to use tab on read. Defaults to false.")
+	input := &csv.Input{}
+	input.AddFlags(flags)
+	output := &csv.Output{}
+	output.AddFlags(flags)
+
 	if err := flags.Parse(args); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
+	input.Files = flags.Args()
 
 	return &csv.UseTabProcess{
 		OnRead: onRead,
-	}, nil
+	}, input, output, nil
 
 }
 
 func main() {
 	var p *csv.UseTabProcess
+	var input *csv.Input
+	var output *csv.Output
 	var err error
 	var errCh = make(chan error, 1)
 
-	if p, err = configure(os.Args[1:]); err == nil {
+	if p, input, output, err = configure(os.Args[1:]); err == nil {
 		var csvReader csv.Reader
 		var csvWriter csv.WriterBuilder
 		if p.OnRead {
-			tmp := encoding.NewReader(os.Stdin)
-			tmp.Comma = TAB
-			csvReader = csv.WithCsvReader(tmp, os.Stdin)
-			csvWriter = csv.WithIoWriter(os.Stdout)
+			input.Delimiter = TAB
 		} else {
-			tmp := encoding.NewWriter(os.Stdout)
-			tmp.Comma = TAB
-			csvWriter = csv.WithCsvWriter(tmp, os.Stdout)
-			csvReader = csv.WithIoReader(os.Stdin)
+			output.Delimiter = TAB
+		}
+		if csvReader, err = input.Open(); err == nil {
+			if csvWriter, err = output.Builder(); err == nil {
+				p.Run(csvReader, csvWriter, errCh)
+				err = <-errCh
+			}
 		}
-		p.Run(csvReader, csvWriter, errCh)
-		err = <-errCh
 	}
 
 	if err != nil {
-		fmt.Printf("fatal: %v\n", err)
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -4,12 +4,13 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/wildducktheories/go-csv"
 )
 
-func configure(args []string) (*csv.JsonToCsvProcess, error) {
+func configure(args []string) (*csv.JsonToCsvProcess, *csv.Output, error) {
 	var baseObject string
 	var columns string
 
@@ -17,36 +18,45 @@ func configure(args []string) (*csv.JsonToCsvProcess, error) {
 
 	flags.StringVar(&baseObject, "base-object-key", "", "The column into which the remainder of each JSON object is read.")
 	flags.StringVar(&columns, "columns", "", "The columns of the CSV file")
+	output := &csv.Output{}
+	output.AddFlags(flags)
 
 	if err := flags.Parse(args); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if columns == "" {
-		return nil, fmt.Errorf("fatal: a --columns parameter must be specified")
+		return nil, nil, fmt.Errorf("a --columns parameter must be specified")
 	}
 
 	if header, err := csv.Parse(columns); err != nil {
-		return nil, fmt.Errorf("fatal: --columns could not be parsed as a CSV record")
+		return nil, nil, fmt.Errorf("--columns could not be parsed as a CSV record")
 	} else {
 
 		return &csv.JsonToCsvProcess{
 			BaseObject: baseObject,
 			Header:     header,
-		}, nil
+		}, output, nil
 	}
 }
 
 func main() {
 	var p *csv.JsonToCsvProcess
+	var output *csv.Output
+	var input io.ReadCloser
+	var builder csv.WriterBuilder
 	var err error
-	if p, err = configure(os.Args[1:]); err == nil {
-		errCh := make(chan error, 1)
-		p.Run(json.NewDecoder(os.Stdin), csv.WithIoWriter(os.Stdout), errCh)
-		err = <-errCh
+	if p, output, err = configure(os.Args[1:]); err == nil {
+		if input, err = csv.Decompress(os.Stdin); err == nil {
+			if builder, err = output.Builder(); err == nil {
+				errCh := make(chan error, 1)
+				p.Run(json.NewDecoder(input), builder, errCh)
+				err = <-errCh
+			}
+		}
 	}
 	if err != nil {
-		fmt.Printf("fatal: %v\n", err)
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,210 @@
+package csv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// The registry of named string comparators.
+var stringComparators = struct {
+	sync.RWMutex
+	byName map[string]StringComparator
+}{
+	byName: map[string]StringComparator{
+		"lexical":            LessStrings,
+		"numeric":            LessNumericStrings,
+		"decimal":            LessDecimalStrings,
+		"natural":            LessNaturalStrings,
+		"case-insensitive":   LessCaseInsensitiveStrings,
+		"accent-insensitive": LessAccentInsensitiveStrings,
+		"version":            LessVersionStrings,
+	},
+}
+
+// Register a string comparator under the specified name, so that it can be selected by name, for
+// example by the --compare option of csv-sort. A comparator registered with the name of an existing
+// comparator replaces it. The following comparators are registered by default:
+//
+//	lexical - LessStrings
+//	numeric - LessNumericStrings
+//	decimal - LessDecimalStrings
+//	natural - LessNaturalStrings
+//	case-insensitive - LessCaseInsensitiveStrings
+//	accent-insensitive - LessAccentInsensitiveStrings
+//	version - LessVersionStrings
+func RegisterStringComparator(name string, less StringComparator) {
+	stringComparators.Lock()
+	defer stringComparators.Unlock()
+	stringComparators.byName[name] = less
+}
+
+// Answer the string comparator registered under the specified name.
+func LookupStringComparator(name string) (StringComparator, error) {
+	stringComparators.RLock()
+	defer stringComparators.RUnlock()
+	if less, ok := stringComparators.byName[name]; ok {
+		return less, nil
+	}
+	return nil, fmt.Errorf("unknown comparator: %s (expected one of: %s)", name, strings.Join(stringComparatorNames(), ", "))
+}
+
+// Answer the names of the registered string comparators, in lexical order.
+func StringComparatorNames() []string {
+	stringComparators.RLock()
+	defer stringComparators.RUnlock()
+	return stringComparatorNames()
+}
+
+func stringComparatorNames() []string {
+	names := make([]string, 0, len(stringComparators.byName))
+	for n := range stringComparators.byName {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Answers true if l is less than r according to a natural comparison, in which each run of
+// digits is compared by its numeric value and the other characters are compared lexically, so
+// that file2 is less than file10.
+func LessNaturalStrings(l, r string) bool {
+	return compareNatural(l, r) < 0
+}
+
+// Answer the leading run of characters of s that are, or are not, digits and the remainder of s.
+func splitRun(s string, digits bool) (string, string) {
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9') == digits {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// Answer a negative number, zero or a positive number according to whether l is less than, equal to
+// or greater than r according to a natural comparison. Runs of digits that differ only in their leading
+// zeros are equal.
+func compareNatural(l, r string) int {
+	for len(l) > 0 && len(r) > 0 {
+		var lr, rr string
+		digits := isDigits(l[:1]) && isDigits(r[:1])
+		lr, l = splitRun(l, digits)
+		rr, r = splitRun(r, digits)
+		if digits {
+			lr = strings.TrimLeft(lr, "0")
+			rr = strings.TrimLeft(rr, "0")
+			if len(lr) != len(rr) {
+				return len(lr) - len(rr)
+			}
+		}
+		if c := strings.Compare(lr, rr); c != 0 {
+			return c
+		}
+	}
+	return len(l) - len(r)
+}
+
+// Answers true if l is less than r according to a lexical comparison of their lower case forms.
+func LessCaseInsensitiveStrings(l, r string) bool {
+	return strings.ToLower(l) < strings.ToLower(r)
+}
+
+// Answers true if l is less than r according to a lexical comparison in which accented Latin letters
+// are replaced by their unaccented forms, for example é by e and ß by ss, and combining marks are ignored.
+func LessAccentInsensitiveStrings(l, r string) bool {
+	return foldAccents(l) < foldAccents(r)
+}
+
+// The replacements of accented Latin letters. Each field is an accented letter followed by its replacement.
+var accentFolds = func() map[rune]string {
+	folds := map[rune]string{}
+	for _, f := range strings.Fields(`
+		ÀA ÁA ÂA ÃA ÄA ÅA ĀA ĂA ĄA àa áa âa ãa äa åa āa ăa ąa ÆAE æae
+		ÇC ĆC ĈC ĊC ČC çc ćc ĉc ċc čc ĎD ĐD ďd đd ÐD ðd
+		ÈE ÉE ÊE ËE ĒE ĔE ĖE ĘE ĚE èe ée êe ëe ēe ĕe ėe ęe ěe
+		ĜG ĞG ĠG ĢG ĝg ğg ġg ģg ĤH ĦH ĥh ħh
+		ÌI ÍI ÎI ÏI ĨI ĪI ĬI ĮI İI ìi íi îi ïi ĩi īi ĭi įi ıi ĴJ ĵj ĶK ķk
+		ĹL ĻL ĽL ĿL ŁL ĺl ļl ľl ŀl łl ÑN ŃN ŅN ŇN ñn ńn ņn ňn
+		ÒO ÓO ÔO ÕO ÖO ØO ŌO ŎO ŐO òo óo ôo õo öo øo ōo ŏo őo ŒOE œoe
+		ŔR ŖR ŘR ŕr ŗr řr ŚS ŜS ŞS ŠS śs ŝs şs šs ßss ŢT ŤT ŦT ţt ťt ŧt ÞTH þth
+		ÙU ÚU ÛU ÜU ŨU ŪU ŬU ŮU ŰU ŲU ùu úu ûu üu ũu ūu ŭu ůu űu ųu ŴW ŵw
+		ÝY ŶY ŸY ýy ÿy ŷy ŹZ ŻZ ŽZ źz żz žz
+	`) {
+		runes := []rune(f)
+		folds[runes[0]] = string(runes[1:])
+	}
+	return folds
+}()
+
+// Answer s with its accented Latin letters replaced and its combining marks removed.
+func foldAccents(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if f, ok := accentFolds[c]; ok {
+			b.WriteString(f)
+		} else if !unicode.Is(unicode.Mn, c) {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// Answers true if l is less than r according to a comparison of version strings of the form
+// [v]release[-prerelease][+build], such as 1.2.10 or v2.0.0-rc.1. The dot separated components
+// of the release are compared naturally, missing components are zero, and a version with a
+// prerelease is less than the same version without one. Build metadata is ignored.
+func LessVersionStrings(l, r string) bool {
+	return compareVersions(l, r) < 0
+}
+
+// Split a version string into its release and prerelease components.
+func splitVersion(s string) ([]string, []string) {
+	s = strings.TrimSpace(s)
+	if len(s) > 1 && (s[0] == 'v' || s[0] == 'V') && isDigits(s[1:2]) {
+		s = s[1:]
+	}
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var prerelease []string
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	return strings.Split(s, "."), prerelease
+}
+
+// Answer a negative number, zero or a positive number according to whether version l is less than,
+// equal to or greater than version r.
+func compareVersions(l, r string) int {
+	lrelease, lpre := splitVersion(l)
+	rrelease, rpre := splitVersion(r)
+	for i := 0; i < len(lrelease) || i < len(rrelease); i++ {
+		lc, rc := "0", "0"
+		if i < len(lrelease) {
+			lc = lrelease[i]
+		}
+		if i < len(rrelease) {
+			rc = rrelease[i]
+		}
+		if c := compareNatural(lc, rc); c != 0 {
+			return c
+		}
+	}
+	if lpre == nil || rpre == nil {
+		if lpre != nil {
+			return -1
+		} else if rpre != nil {
+			return 1
+		}
+		return 0
+	}
+	for i := 0; i < len(lpre) && i < len(rpre); i++ {
+		if c := compareNatural(lpre[i], rpre[i]); c != 0 {
+			return c
+		}
+	}
+	return len(lpre) - len(rpre)
+}
@@ -0,0 +1,61 @@
+package csv
+
+import "testing"
+
+func TestStringComparators(t *testing.T) {
+	cases := []struct {
+		name string
+		l, r string
+		less bool
+	}{
+		{"natural", "file2", "file10", true},
+		{"natural", "file10", "file2", false},
+		{"natural", "file02", "file2", false},
+		{"natural", "file2", "file02", false},
+		{"natural", "file007", "file10", true},
+		{"natural", "a", "a1", true},
+		{"natural", "a1b2", "a1b10", true},
+		{"natural", "x1y", "xay", true},
+		{"natural", "", "0", true},
+		{"case-insensitive", "apple", "Banana", true},
+		{"case-insensitive", "ABC", "abc", false},
+		{"accent-insensitive", "Émile", "Eric", true},
+		{"accent-insensitive", "cafe", "café", false},
+		{"accent-insensitive", "Straße", "Strasse", false},
+		{"accent-insensitive", "café", "cafe", false},
+		{"version", "1.2.9", "1.2.10", true},
+		{"version", "2.0", "10.0", true},
+		{"version", "v1.2", "1.2.0", false},
+		{"version", "1.2.0", "v1.2", false},
+		{"version", "1.0.0-alpha", "1.0.0-alpha.1", true},
+		{"version", "1.0.0-alpha.1", "1.0.0-alpha.beta", true},
+		{"version", "1.0.0-alpha.2", "1.0.0-alpha.10", true},
+		{"version", "1.0.0-beta.11", "1.0.0-rc.1", true},
+		{"version", "1.0.0-rc.1", "1.0.0", true},
+		{"version", "1.0.0", "1.0.0-rc.1", false},
+		{"version", "1.0.0+build.5", "1.0.0", false},
+	}
+	for _, c := range cases {
+		less, err := LookupStringComparator(c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := less(c.l, c.r); got != c.less {
+			t.Fatalf("%s: %q < %q: %v, expected %v", c.name, c.l, c.r, got, c.less)
+		}
+	}
+}
+
+func TestRegisterStringComparator(t *testing.T) {
+	if _, err := LookupStringComparator("length"); err == nil {
+		t.Fatalf("expected an error for an unregistered comparator")
+	}
+	RegisterStringComparator("length", func(l, r string) bool { return len(l) < len(r) })
+	less, err := LookupStringComparator("length")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !less("b", "aa") {
+		t.Fatalf("expected the registered comparator")
+	}
+}
@@ -0,0 +1,106 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+)
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+)
+
+// Answers true if the specified bytes are a zlib header that uses the deflate method
+// with a 32K window and no preset dictionary, which is what zlib writers generate by default.
+func isZlibHeader(b []byte) bool {
+	return len(b) == 2 && b[0] == 0x78 && b[1]&0x20 == 0 && (uint(b[0])<<8|uint(b[1]))%31 == 0
+}
+
+type decompressor struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d *decompressor) Close() error {
+	var err error
+	for _, c := range d.closers {
+		if e := c.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Decompress answers a reader that yields the decompressed content of the specified reader if its
+// content begins with the magic bytes of a gzip, bzip2 or zlib stream and the unchanged content of
+// the specified reader otherwise. Closing the result closes the specified reader.
+func Decompress(r io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	magic, _ := buffered.Peek(3)
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		if z, err := gzip.NewReader(buffered); err != nil {
+			return nil, err
+		} else {
+			return &decompressor{Reader: z, closers: []io.Closer{z, r}}, nil
+		}
+	case len(magic) == 3 && bytes.Equal(magic, bzip2Magic):
+		return &decompressor{Reader: bzip2.NewReader(buffered), closers: []io.Closer{r}}, nil
+	case len(magic) >= 2 && isZlibHeader(magic[:2]):
+		if z, err := zlib.NewReader(buffered); err != nil {
+			return nil, err
+		} else {
+			return &decompressor{Reader: z, closers: []io.Closer{z, r}}, nil
+		}
+	default:
+		return &decompressor{Reader: buffered, closers: []io.Closer{r}}, nil
+	}
+}
+
+// WithDecompressingIoReader creates a csv Reader from the specified io Reader, which may
+// contain a gzip, bzip2 or zlib compressed CSV stream.
+func WithDecompressingIoReader(r io.ReadCloser) (Reader, error) {
+	if d, err := Decompress(r); err != nil {
+		return nil, err
+	} else {
+		return WithIoReader(d), nil
+	}
+}
+
+type gzipWriteCloser struct {
+	*gzip.Writer
+	closer io.Closer
+}
+
+func (g *gzipWriteCloser) Close() error {
+	err := g.Writer.Close()
+	if e := g.closer.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// Answer a writer that writes a gzip compressed copy of its input to the specified writer, using
+// the specified compression level (per compress/gzip). Closing the result closes the specified writer.
+func GzipWriter(w io.WriteCloser, level int) (io.WriteCloser, error) {
+	if z, err := gzip.NewWriterLevel(w, level); err != nil {
+		return nil, err
+	} else {
+		return &gzipWriteCloser{Writer: z, closer: w}, nil
+	}
+}
+
+// Answer a Writer for the gzip compressed CSV stream constrained by the specified header, using the
+// specified io writer and compression level (per compress/gzip).
+func WithGzipIoWriter(w io.WriteCloser, level int) (WriterBuilder, error) {
+	if z, err := GzipWriter(w, level); err != nil {
+		return nil, err
+	} else {
+		return WithIoWriter(z), nil
+	}
+}
@@ -0,0 +1,153 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// A ConcatMode determines how a ConcatProcess reconciles the headers of its input streams.
+type ConcatMode int
+
+const (
+	// ConcatStrict requires every input stream to have the same header.
+	ConcatStrict ConcatMode = iota
+	// ConcatUnion outputs every column that occurs in any input stream. Columns missing from
+	// an input stream are left empty.
+	ConcatUnion
+	// ConcatIntersection outputs only the columns that occur in every input stream.
+	ConcatIntersection
+)
+
+// Answer the ConcatMode with the specified name, one of: strict, union or intersection.
+func ParseConcatMode(s string) (ConcatMode, error) {
+	switch s {
+	case "strict":
+		return ConcatStrict, nil
+	case "union":
+		return ConcatUnion, nil
+	case "intersection":
+		return ConcatIntersection, nil
+	default:
+		return ConcatStrict, fmt.Errorf("unknown concatenation mode: %s", s)
+	}
+}
+
+// Answer the name of the mode.
+func (m ConcatMode) String() string {
+	switch m {
+	case ConcatUnion:
+		return "union"
+	case ConcatIntersection:
+		return "intersection"
+	default:
+		return "strict"
+	}
+}
+
+// Set the mode from its name. Together with String, this allows a ConcatMode to be used as a flag.Value.
+func (m *ConcatMode) Set(s string) (err error) {
+	*m, err = ParseConcatMode(s)
+	return err
+}
+
+// A ConcatProcess copies each of several streams of CSV records, in turn, to a single output stream.
+// The fields of each record are aligned with the output header by column name, and the output header
+// is derived from the headers of the input streams according to the Mode. Input streams without a
+// header, such as empty files, are ignored.
+//
+// If SourceColumn is specified, an additional column of that name is appended to the output header
+// and, for each record, it is filled with the element of Sources that describes the input stream the
+// record was read from (or the index of the input stream if Sources has no such element).
+type ConcatProcess struct {
+	Mode         ConcatMode // the mode used to reconcile the headers of the input streams
+	SourceColumn string     // the name of an additional column that identifies the source of each record
+	Sources      []string   // the names of the input streams, used to fill SourceColumn
+}
+
+// Answer the output header for the specified readers.
+func (p *ConcatProcess) header(readers []Reader) ([]string, error) {
+	var result []string
+	for i, r := range readers {
+		h := r.Header()
+		if len(h) == 0 {
+			// an empty input has neither a header nor records
+			continue
+		} else if result == nil {
+			result = h
+			continue
+		}
+		switch p.Mode {
+		case ConcatStrict:
+			if Format(h) != Format(result) {
+				return nil, fmt.Errorf("%s: header %s does not match %s", p.source(i), Format(h), Format(result))
+			}
+		case ConcatUnion:
+			result = utils.Union(result, h)
+		case ConcatIntersection:
+			result, _, _ = utils.Intersect(h, result)
+		}
+	}
+
+	if result == nil {
+		result = []string{}
+	}
+
+	if p.SourceColumn != "" {
+		if utils.NewIndex(result).Contains(p.SourceColumn) {
+			return nil, fmt.Errorf("%s already exists in data header", p.SourceColumn)
+		}
+		extend := make([]string, len(result)+1)
+		copy(extend, result)
+		extend[len(result)] = p.SourceColumn
+		result = extend
+	}
+	return result, nil
+}
+
+// Answer a description of the ith input stream.
+func (p *ConcatProcess) source(i int) string {
+	if i < len(p.Sources) {
+		return p.Sources[i]
+	}
+	return strconv.Itoa(i)
+}
+
+// Run the concatenation process against the specified readers, writing the concatenated
+// stream to a Writer constructed from the specified builder.
+func (p *ConcatProcess) Run(readers []Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		for _, r := range readers {
+			defer r.Close()
+		}
+
+		header, err := p.header(readers)
+		if err != nil {
+			return err
+		}
+
+		writer := builder(header)
+		defer func() {
+			writer.Close(err)
+		}()
+
+		for i, r := range readers {
+			source := p.source(i)
+			for data := range r.C() {
+				o := writer.Blank()
+				o.PutAll(data)
+				if p.SourceColumn != "" {
+					o.Put(p.SourceColumn, source)
+				}
+				if err := writer.Write(o); err != nil {
+					return err
+				}
+			}
+			if err := r.Error(); err != nil {
+				return fmt.Errorf("%s: %v", source, err)
+			}
+		}
+		return nil
+	}()
+}
@@ -16,9 +16,13 @@ import (
 // the value will be encoded as the corresponding JSON object, otherwise it will be encoded as a string.
 // Use --strings to force all column values to be encoded as JSON strings.
 //
+// Numbers are converted to float64 values, which may not represent them exactly. If Decimal is
+// specified, each number is instead copied verbatim into the JSON object, preserving its precision and scale.
+//
 type CsvToJsonProcess struct {
 	BaseObject  string
 	StringsOnly bool
+	Decimal     bool
 }
 
 func (proc *CsvToJsonProcess) writeToMap(m map[string]interface{}, p []string, v interface{}) {
@@ -104,7 +108,9 @@ func (p *CsvToJsonProcess) Run(reader Reader, encoder *json.Encoder, errCh chan<
 						ov = aj
 					}
 				} else if numberMatcher.MatchString(v) {
-					if _, err := fmt.Sscanf(v, "%f", &f); err == nil {
+					if p.Decimal {
+						ov = json.Number(strings.TrimSpace(v))
+					} else if _, err := fmt.Sscanf(v, "%f", &f); err == nil {
 						ov = f
 					}
 				}
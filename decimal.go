@@ -0,0 +1,175 @@
+package csv
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// The number of fractional digits, beyond the scale of the dividend, to which the
+// quotient of a Decimal division that does not terminate is rounded.
+const decimalQuotientDigits = 16
+
+// The largest exponent accepted by ParseDecimal.
+const maxDecimalExponent = 1000
+
+// A Decimal is an exact decimal number, represented as an arbitrary precision integer and
+// a scale, the number of digits to the right of the decimal point. Unlike a float64, a Decimal
+// represents every decimal string exactly and it preserves the scale of the string from which
+// it was parsed, so that 1.50 is formatted as 1.50 rather than 1.5.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// Answer the Decimal represented by the specified string. Surrounding white space is ignored.
+// The string consists of an optional sign, one or more digits with an optional decimal point
+// and an optional exponent, for example: -12.50 or 1.5e3. A number with an exponent is scaled
+// so that it can be formatted without an exponent.
+func ParseDecimal(s string) (*Decimal, error) {
+	t := strings.TrimSpace(s)
+	mantissa := t
+	exponent := 0
+	if i := strings.IndexAny(t, "eE"); i >= 0 {
+		mantissa = t[:i]
+		var err error
+		if exponent, err = strconv.Atoi(t[i+1:]); err != nil || exponent > maxDecimalExponent || exponent < -maxDecimalExponent {
+			return nil, fmt.Errorf("not a decimal: %s", s)
+		}
+	}
+
+	sign := ""
+	if len(mantissa) > 0 && (mantissa[0] == '-' || mantissa[0] == '+') {
+		if mantissa[0] == '-' {
+			sign = "-"
+		}
+		mantissa = mantissa[1:]
+	}
+
+	whole, fraction := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		whole, fraction = mantissa[:i], mantissa[i+1:]
+	}
+	if len(whole)+len(fraction) == 0 || !isDigits(whole) || !isDigits(fraction) {
+		return nil, fmt.Errorf("not a decimal: %s", s)
+	}
+
+	unscaled, _ := new(big.Int).SetString(sign+whole+fraction, 10)
+	d := &Decimal{unscaled: unscaled, scale: len(fraction) - exponent}
+	if d.scale < 0 {
+		d.unscaled.Mul(d.unscaled, pow10(-d.scale))
+		d.scale = 0
+	}
+	return d, nil
+}
+
+// Answer true if s consists only of ASCII digits.
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Answer 10 raised to the power of n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Answer the number of digits to the right of the decimal point.
+func (d *Decimal) Scale() int {
+	return d.scale
+}
+
+// Answer -1, 0 or +1 according to whether the receiver is negative, zero or positive.
+func (d *Decimal) Sign() int {
+	return d.unscaled.Sign()
+}
+
+// Answer the unscaled value of the receiver adjusted to the specified, larger, scale.
+func (d *Decimal) rescale(scale int) *big.Int {
+	if scale == d.scale {
+		return d.unscaled
+	}
+	return new(big.Int).Mul(d.unscaled, pow10(scale-d.scale))
+}
+
+// Answer -1, 0 or +1 according to whether the receiver is less than, equal to or greater than o.
+func (d *Decimal) Cmp(o *Decimal) int {
+	scale := d.scale
+	if o.scale > scale {
+		scale = o.scale
+	}
+	return d.rescale(scale).Cmp(o.rescale(scale))
+}
+
+// Answer the sum of the receiver and o. The scale of the sum is the larger of the two scales.
+func (d *Decimal) Add(o *Decimal) *Decimal {
+	scale := d.scale
+	if o.scale > scale {
+		scale = o.scale
+	}
+	return &Decimal{unscaled: new(big.Int).Add(d.rescale(scale), o.rescale(scale)), scale: scale}
+}
+
+// Answer the quotient of the receiver and n. The scale of the quotient is the smallest scale, not less
+// than that of the receiver, at which the quotient is exact. If there is no such scale within 16 digits
+// of the scale of the receiver, the quotient is rounded, half away from zero, at that scale.
+func (d *Decimal) Quo(n int64) *Decimal {
+	if n == 0 {
+		panic("division by zero")
+	}
+	divisor := big.NewInt(n)
+	q, r := new(big.Int), new(big.Int)
+	unscaled := new(big.Int).Set(d.unscaled)
+	for scale := d.scale; ; scale++ {
+		q.QuoRem(unscaled, divisor, r)
+		if r.Sign() == 0 {
+			return &Decimal{unscaled: q, scale: scale}
+		}
+		if scale == d.scale+decimalQuotientDigits {
+			// round half away from zero
+			r.Abs(r).Mul(r, big.NewInt(2))
+			if r.Cmp(new(big.Int).Abs(divisor)) >= 0 {
+				if unscaled.Sign()*divisor.Sign() < 0 {
+					q.Sub(q, big.NewInt(1))
+				} else {
+					q.Add(q, big.NewInt(1))
+				}
+			}
+			return &Decimal{unscaled: q, scale: scale}
+		}
+		unscaled.Mul(unscaled, big.NewInt(10))
+	}
+}
+
+// Answer the receiver formatted, without an exponent, with exactly Scale() fractional digits.
+func (d *Decimal) String() string {
+	digits := new(big.Int).Abs(d.unscaled).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if d.unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Answers true if the decimal value of l is less than r according to an exact decimal
+// comparison (if l and r are both parseable as decimals) or according to a lexical
+// comparison otherwise.
+func LessDecimalStrings(l, r string) bool {
+	if ld, err := ParseDecimal(l); err != nil {
+		return LessStrings(l, r)
+	} else if rd, err := ParseDecimal(r); err != nil {
+		return LessStrings(l, r)
+	} else {
+		return ld.Cmp(rd) < 0
+	}
+}
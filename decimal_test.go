@@ -0,0 +1,130 @@
+package csv
+
+import "testing"
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		input  string
+		scale  int
+		output string
+	}{
+		{"0", 0, "0"},
+		{"1.50", 2, "1.50"},
+		{"-12.345", 3, "-12.345"},
+		{"+7", 0, "7"},
+		{" 3.0 ", 1, "3.0"},
+		{".5", 1, "0.5"},
+		{"5.", 0, "5"},
+		{"0.001", 3, "0.001"},
+		{"-0.05", 2, "-0.05"},
+		{"1.5e3", 0, "1500"},
+		{"1.25E-1", 3, "0.125"},
+		{"12345678901234567890.12", 2, "12345678901234567890.12"},
+	}
+	for _, c := range cases {
+		d, err := ParseDecimal(c.input)
+		if err != nil {
+			t.Fatalf("%q: %v", c.input, err)
+		}
+		if d.Scale() != c.scale {
+			t.Fatalf("%q: scale %d, expected %d", c.input, d.Scale(), c.scale)
+		}
+		if d.String() != c.output {
+			t.Fatalf("%q: formatted as %q, expected %q", c.input, d.String(), c.output)
+		}
+	}
+}
+
+func TestParseDecimalErrors(t *testing.T) {
+	for _, input := range []string{"", "-", ".", "abc", "1.2.3", "1e", "1e1.5", "0x10", "1,000", "NaN", "1e100000"} {
+		if _, err := ParseDecimal(input); err == nil {
+			t.Fatalf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	parse := func(s string) *Decimal {
+		d, err := ParseDecimal(s)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		return d
+	}
+
+	if sum := parse("0.1").Add(parse("0.2")); sum.String() != "0.3" || sum.Cmp(parse("0.3")) != 0 {
+		t.Fatalf("0.1+0.2: %s", sum)
+	}
+	if sum := parse("1.50").Add(parse("2.5")); sum.String() != "4.00" {
+		t.Fatalf("1.50+2.5: %s", sum)
+	}
+
+	quotients := []struct {
+		dividend string
+		divisor  int64
+		quotient string
+	}{
+		{"3", 2, "1.5"},
+		{"4.00", 2, "2.00"},
+		{"10", 3, "3.3333333333333333"},
+		{"20", 3, "6.6666666666666667"},
+		{"-20", 3, "-6.6666666666666667"},
+		{"1", -8, "-0.125"},
+	}
+	for _, c := range quotients {
+		if q := parse(c.dividend).Quo(c.divisor); q.String() != c.quotient {
+			t.Fatalf("%s/%d: %s, expected %s", c.dividend, c.divisor, q, c.quotient)
+		}
+	}
+}
+
+func TestLessDecimalStrings(t *testing.T) {
+	cases := []struct {
+		l, r string
+		less bool
+	}{
+		{"0.3", "0.30000000000000004", true},
+		{"1.0", "1", false},
+		{"1", "1.0", false},
+		{"-1.5", "-1.25", true},
+		{"9", "10", true},
+		{"12345678901234567.88", "12345678901234567.89", true},
+		{"1e2", "99.99", false},
+		{"abc", "1", false},
+		{"1", "abc", true},
+	}
+	for _, c := range cases {
+		if less := LessDecimalStrings(c.l, c.r); less != c.less {
+			t.Fatalf("%s < %s: %v, expected %v", c.l, c.r, less, c.less)
+		}
+	}
+}
+
+func TestDecimalAggregation(t *testing.T) {
+	cases := []struct {
+		aggregation string
+		values      []string
+		value       string
+	}{
+		{"sum", []string{"0.1", "0.2"}, "0.3"},
+		{"sum", []string{"1.50", "", "2.50"}, "4.00"},
+		{"mean", []string{"1", "2"}, "1.5"},
+		{"min", []string{"10", "9.5", "100"}, "9.5"},
+		{"max", []string{"12345678901234567.88", "12345678901234567.89"}, "12345678901234567.89"},
+	}
+	for _, c := range cases {
+		factory, err := NewAggregatorFactory(c.aggregation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := factory()
+		for _, v := range c.values {
+			if err := a.Add(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if a.Value() != c.value {
+			t.Fatalf("%s of %v: %s, expected %s", c.aggregation, c.values, a.Value(), c.value)
+		}
+	}
+}
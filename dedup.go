@@ -0,0 +1,181 @@
+package csv
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// A DedupPolicy determines which of a set of records with the same key is kept by a DedupProcess.
+type DedupPolicy int
+
+const (
+	KeepFirst DedupPolicy = iota // keep the first record with each key
+	KeepLast                     // keep the last record with each key
+	KeepMax                      // keep the record with the greatest numeric value of the MaxColumn
+)
+
+// A DedupMode determines how a DedupProcess detects duplicate keys.
+type DedupMode int
+
+const (
+	// DedupExact remembers every key in memory.
+	DedupExact DedupMode = iota
+	// DedupSorted only compares each record with the preceding record, so requires an input stream
+	// that is sorted by the key, but only uses memory for one group of records at a time.
+	DedupSorted
+	// DedupApproximate remembers keys in a Bloom filter of bounded size. Some records with distinct keys
+	// are dropped as duplicates, with a probability that is determined by FalsePositiveRate. This mode
+	// only supports the KeepFirst policy.
+	DedupApproximate
+)
+
+// Given a header-prefixed input stream of CSV records and the specification of a key (Keys), generate an
+// output stream which contains exactly one record for each distinct key. If no Keys are specified, the
+// entire record is used as the key.
+//
+// The Policy determines which record with each key is kept. Records are written in the order in which
+// the kept records occur in the input stream.
+type DedupProcess struct {
+	Keys              []string    // the columns of the key. The entire record is used if empty.
+	Policy            DedupPolicy // determines which record with each key is kept
+	MaxColumn         string      // the column compared by the KeepMax policy
+	Mode              DedupMode   // determines how duplicate keys are detected
+	Capacity          int         // the expected number of distinct keys (DedupApproximate only). DefaultDedupCapacity if zero.
+	FalsePositiveRate float64     // the probability that a distinct key is treated as a duplicate (DedupApproximate only). DefaultFalsePositiveRate if zero.
+}
+
+// The defaults used by a DedupProcess in DedupApproximate mode.
+const (
+	DefaultDedupCapacity     = 1000000
+	DefaultFalsePositiveRate = 0.01
+)
+
+// Answer true if the candidate should replace the current record according to the policy.
+func (p *DedupProcess) replaces(current Record, candidate Record) bool {
+	switch p.Policy {
+	case KeepLast:
+		return true
+	case KeepMax:
+		return LessNumericStrings(current.Get(p.MaxColumn), candidate.Get(p.MaxColumn))
+	default:
+		return false
+	}
+}
+
+func (p *DedupProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+		if _, x, _ := utils.Intersect(p.Keys, dataHeader); len(x) > 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+		if p.Policy == KeepMax && !utils.NewIndex(dataHeader).Contains(p.MaxColumn) {
+			return fmt.Errorf("%s does not exist in the data header", p.MaxColumn)
+		}
+		if p.Mode == DedupApproximate && p.Policy != KeepFirst {
+			return fmt.Errorf("approximate deduplication only supports keeping the first record")
+		}
+
+		writer := builder(dataHeader)
+		defer writer.Close(err)
+
+		key := make([]string, len(p.Keys))
+		tokey := func(data Record) string {
+			if len(p.Keys) == 0 {
+				return Format(data.AsSlice())
+			}
+			for i, k := range p.Keys {
+				key[i] = data.Get(k)
+			}
+			return Format(key)
+		}
+
+		switch p.Mode {
+		case DedupSorted:
+			var kept Record
+			var keptKey string
+			for data := range reader.C() {
+				k := tokey(data)
+				if kept != nil && k != keptKey {
+					if err := writer.Write(kept); err != nil {
+						return err
+					}
+					kept = nil
+				}
+				if kept == nil || p.replaces(kept, data) {
+					kept = data
+					keptKey = k
+				}
+			}
+			if kept != nil {
+				if err := writer.Write(kept); err != nil {
+					return err
+				}
+			}
+		case DedupApproximate:
+			capacity, rate := p.Capacity, p.FalsePositiveRate
+			if capacity == 0 {
+				capacity = DefaultDedupCapacity
+			}
+			if rate == 0 {
+				rate = DefaultFalsePositiveRate
+			}
+			filter, err := newBloomFilter(capacity, rate)
+			if err != nil {
+				return err
+			}
+			for data := range reader.C() {
+				if !filter.add(tokey(data)) {
+					if err := writer.Write(data); err != nil {
+						return err
+					}
+				}
+			}
+		default:
+			if p.Policy == KeepFirst {
+				seen := map[string]bool{}
+				for data := range reader.C() {
+					k := tokey(data)
+					if !seen[k] {
+						seen[k] = true
+						if err := writer.Write(data); err != nil {
+							return err
+						}
+					}
+				}
+			} else {
+				kept := map[string]*sampledRecord{}
+				n := 0
+				for data := range reader.C() {
+					k := tokey(data)
+					if current, ok := kept[k]; !ok {
+						kept[k] = &sampledRecord{record: data, index: n}
+					} else if p.replaces(current.record, data) {
+						current.record = data
+						current.index = n
+					}
+					n++
+				}
+				if err := reader.Error(); err != nil {
+					return err
+				}
+				all := make([]*sampledRecord, 0, len(kept))
+				for _, s := range kept {
+					all = append(all, s)
+				}
+				sort.Slice(all, func(i, j int) bool {
+					return all[i].index < all[j].index
+				})
+				for _, s := range all {
+					if err := writer.Write(s.record); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return reader.Error()
+	}()
+}
@@ -0,0 +1,146 @@
+package csv
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// The number of counters used by a FrequencyProcess in approximate mode by default.
+const DefaultFrequencyCapacity = 10000
+
+// Given a header-prefixed input stream of CSV records, FrequencyProcess counts the occurrences of each
+// distinct combination of the values of the specified columns (Keys), or of entire records if no Keys
+// are specified. The output stream contains one record for each combination, containing the key columns
+// and the count (in CountColumn), in order of decreasing count. If TopN is specified, only the TopN most
+// frequent combinations are written.
+//
+// If PercentColumn is specified, an additional column of that name contains the count as a percentage of
+// the number of input records. If CumulativeColumn is specified, an additional column of that name contains
+// the running total of the percentages of the output records.
+//
+// By default, every distinct combination is counted exactly in memory. If Approximate is true, at most
+// Capacity combinations are tracked at once (per the space-saving algorithm), so memory use is bounded but
+// the counts may be overestimated. Any combination that accounts for more than 1/Capacity of the records
+// is guaranteed to be reported.
+type FrequencyProcess struct {
+	Keys             []string // the columns whose values are counted. The entire record is used if empty.
+	TopN             int      // the number of combinations to write. Unbounded if zero.
+	CountColumn      string   // the name of the count column. "count" if empty.
+	PercentColumn    string   // the name of an additional percentage column
+	CumulativeColumn string   // the name of an additional cumulative percentage column
+	Approximate      bool     // count in bounded memory
+	Capacity         int      // the number of combinations tracked in approximate mode. DefaultFrequencyCapacity if zero.
+}
+
+// Format a percentage, rounded to 4 decimal places.
+func formatPercent(f float64) string {
+	return formatFloat(math.Round(f*10000) / 10000)
+}
+
+func (p *FrequencyProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+		keys := p.Keys
+		if len(keys) == 0 {
+			keys = dataHeader
+		} else if _, x, _ := utils.Intersect(keys, dataHeader); len(x) != 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+
+		countColumn := p.CountColumn
+		if countColumn == "" {
+			countColumn = "count"
+		}
+		outputHeader := append(append([]string{}, keys...), countColumn)
+		if p.PercentColumn != "" {
+			outputHeader = append(outputHeader, p.PercentColumn)
+		}
+		if p.CumulativeColumn != "" {
+			outputHeader = append(outputHeader, p.CumulativeColumn)
+		}
+		if len(utils.NewIndex(outputHeader)) != len(outputHeader) {
+			return fmt.Errorf("output header contains duplicate columns: %s", Format(outputHeader))
+		}
+
+		var exact map[string]int
+		var approximate *spaceSaving
+		if p.Approximate {
+			capacity := p.Capacity
+			if capacity == 0 {
+				capacity = DefaultFrequencyCapacity
+			}
+			if capacity < p.TopN {
+				capacity = p.TopN
+			}
+			approximate = newSpaceSaving(capacity)
+		} else {
+			exact = map[string]int{}
+		}
+
+		total := 0
+		values := make([]string, len(keys))
+		for data := range reader.C() {
+			total++
+			for i, k := range keys {
+				values[i] = data.Get(k)
+			}
+			if key := Format(values); exact != nil {
+				exact[key]++
+			} else {
+				approximate.add(key)
+			}
+		}
+		if err = reader.Error(); err != nil {
+			return err
+		}
+
+		var counts []frequentValue
+		if exact != nil {
+			counts = make([]frequentValue, 0, len(exact))
+			for v, n := range exact {
+				counts = append(counts, frequentValue{value: v, count: n})
+			}
+			sortFrequentValues(counts)
+		} else {
+			counts = approximate.top()
+		}
+		if p.TopN > 0 && len(counts) > p.TopN {
+			counts = counts[:p.TopN]
+		}
+
+		writer := builder(outputHeader)
+		defer writer.Close(err)
+
+		cumulative := 0
+		for _, c := range counts {
+			fields := make([]string, len(keys))
+			if c.value != "" {
+				// Format answers an empty string only for a single empty value
+				if fields, err = Parse(c.value); err != nil {
+					return err
+				}
+			}
+			o := writer.Blank()
+			for i, k := range keys {
+				o.Put(k, fields[i])
+			}
+			o.Put(countColumn, strconv.Itoa(c.count))
+			cumulative += c.count
+			if p.PercentColumn != "" {
+				o.Put(p.PercentColumn, formatPercent(100*float64(c.count)/float64(total)))
+			}
+			if p.CumulativeColumn != "" {
+				o.Put(p.CumulativeColumn, formatPercent(100*float64(cumulative)/float64(total)))
+			}
+			if err = writer.Write(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+}
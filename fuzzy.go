@@ -0,0 +1,287 @@
+package csv
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// Fuzzy specifies a fuzzy join. Rather than requiring the values of a text column of each stream to be
+// equal, a fuzzy join matches each left record with every right record whose RightKey value is similar
+// to the left record's LeftKey value, according to the named Metric. Values are normalized before they are
+// compared by converting them to lower case and collapsing runs of white space.
+//
+// A pair of records matches if the similarity of their values, a number between 0 and 1, is at least
+// Threshold. If ScoreColumn is specified, the similarity of each matching pair, rounded to 4 decimal
+// places, is written into an additional column of that name.
+//
+// Every left record is compared with every right record that has equal join keys, so the join keys act as
+// blocking keys that limit the number of comparisons. Both streams must be sorted by the join keys, and
+// the right records of each block are held in memory.
+//
+// If the prefixed text columns have the same name, the right text column is omitted from the output stream,
+// except that it supplies the value of the left text column for right records that match no left record.
+type Fuzzy struct {
+	LeftKey     string  // the text column of the left stream
+	RightKey    string  // the text column of the right stream
+	Metric      string  // the similarity metric: levenshtein or jaro-winkler. levenshtein is used if empty.
+	Threshold   float64 // the least similarity of a matching pair
+	ScoreColumn string  // the name of an additional column that contains the similarity of each matching pair
+}
+
+// Answer the similarity function with the specified name.
+func similarity(metric string) (func(a, b string) float64, error) {
+	switch metric {
+	case "", "levenshtein":
+		return LevenshteinSimilarity, nil
+	case "jaro-winkler":
+		return JaroWinklerSimilarity, nil
+	default:
+		return nil, fmt.Errorf("unknown similarity metric: %s (expected one of: levenshtein, jaro-winkler)", metric)
+	}
+}
+
+// Normalize a value for comparison.
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// Answer the Levenshtein similarity of two strings: 1 less the edit distance between the strings
+// divided by the length, in runes, of the longer string.
+func LevenshteinSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < curr[j] {
+				curr[j] = d
+			}
+			if d := curr[j-1] + 1; d < curr[j] {
+				curr[j] = d
+			}
+		}
+		prev, curr = curr, prev
+	}
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// Answer the Jaro-Winkler similarity of two strings, using a prefix scale of 0.1 and a maximum
+// prefix length of 4.
+func JaroWinklerSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	} else if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := len(ra)
+	if len(rb) > window {
+		window = len(rb)
+	}
+	window = window/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := i-window, i+window+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(rb) {
+			hi = len(rb)
+		}
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < 4 && prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func (p *Join) runFuzzy(left Reader, right Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer left.Close()
+		defer right.Close()
+
+		f := p.Fuzzy
+
+		if _, x, _ := utils.Intersect(append(append([]string{}, p.LeftKeys...), f.LeftKey), left.Header()); len(x) != 0 {
+			return fmt.Errorf("left: %s does not exist in the data header", Format(x))
+		}
+		if _, x, _ := utils.Intersect(append(append([]string{}, p.RightKeys...), f.RightKey), right.Header()); len(x) != 0 {
+			return fmt.Errorf("right: %s does not exist in the data header", Format(x))
+		}
+
+		score, err := similarity(f.Metric)
+		if err != nil {
+			return err
+		}
+
+		keyHeader, leftHeader, rightHeader := p.headers(left.Header(), right.Header())
+
+		// omit the right text column if it has the same name as the left text column
+		sharedText := p.LeftPrefix+f.LeftKey == p.RightPrefix+f.RightKey
+		if sharedText {
+			_, rightHeader, _ = utils.Intersect(rightHeader, []string{f.RightKey})
+		}
+
+		outputHeader := p.outputHeader(keyHeader, leftHeader, rightHeader)
+		if f.ScoreColumn != "" {
+			outputHeader = append(outputHeader, f.ScoreColumn)
+		}
+		if err = checkDuplicateColumns(outputHeader); err != nil {
+			return err
+		}
+
+		less := p.less()
+		leftBlank := NewRecordBuilder(left.Header())([]string{})
+		rightBlank := NewRecordBuilder(right.Header())([]string{})
+
+		writer := builder(outputHeader)
+		defer writer.Close(err)
+
+		record := p.recordWriter(writer, keyHeader, leftHeader, rightHeader)
+		w := func(k []string, l, r Record, s string) error {
+			o := record(k, l, r)
+			if sharedText && l == leftBlank {
+				o.Put(p.LeftPrefix+f.LeftKey, r.Get(f.RightKey))
+			}
+			if f.ScoreColumn != "" {
+				o.Put(f.ScoreColumn, s)
+			}
+			return writer.Write(o)
+		}
+
+		unmatched := func(k []string, group []Record, isLeft bool) error {
+			for _, r := range group {
+				if isLeft && p.LeftOuter {
+					if err := w(k, r, rightBlank, ""); err != nil {
+						return err
+					}
+				} else if !isLeft && p.RightOuter {
+					if err := w(k, leftBlank, r, ""); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+
+		leftG := &groupReader{reader: left, less: less, tokey: (&SortKeys{Keys: p.LeftKeys}).AsStringProjection()}
+		rightG := &groupReader{reader: right, less: less, tokey: (&SortKeys{Keys: p.RightKeys}).AsStringProjection()}
+
+		for leftG.hasNext() && rightG.hasNext() {
+			if less(leftG.key, rightG.key) {
+				if err := unmatched(leftG.key, leftG.get(), true); err != nil {
+					return err
+				}
+			} else if less(rightG.key, leftG.key) {
+				if err := unmatched(rightG.key, rightG.get(), false); err != nil {
+					return err
+				}
+			} else {
+				// compare each pair of records in the block
+				k := leftG.key
+				rg := rightG.get()
+				rightValues := make([]string, len(rg))
+				for i, r := range rg {
+					rightValues[i] = normalizeText(r.Get(f.RightKey))
+				}
+				rightMatched := make([]bool, len(rg))
+				for _, l := range leftG.get() {
+					v := normalizeText(l.Get(f.LeftKey))
+					matched := false
+					for i, r := range rg {
+						if s := score(v, rightValues[i]); s >= f.Threshold {
+							matched = true
+							rightMatched[i] = true
+							if err := w(k, l, r, formatFloat(math.Round(s*10000)/10000)); err != nil {
+								return err
+							}
+						}
+					}
+					if !matched && p.LeftOuter {
+						if err := w(k, l, rightBlank, ""); err != nil {
+							return err
+						}
+					}
+				}
+				for i, r := range rg {
+					if !rightMatched[i] && p.RightOuter {
+						if err := w(k, leftBlank, r, ""); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+		for leftG.hasNext() {
+			if err := unmatched(leftG.key, leftG.get(), true); err != nil {
+				return err
+			}
+		}
+		for rightG.hasNext() {
+			if err := unmatched(rightG.key, rightG.get(), false); err != nil {
+				return err
+			}
+		}
+
+		if err = left.Error(); err != nil {
+			return err
+		}
+		return right.Error()
+	}()
+}
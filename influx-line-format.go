@@ -8,7 +8,6 @@ import (
 	"regexp"
 	"sort"
 	"strconv"
-	"time"
 )
 
 // InfluxLineFormatProcess is a process which converts a CSV file into
@@ -48,7 +47,7 @@ func (p *InfluxLineFormatProcess) Run(reader Reader, out io.Writer, errCh chan<-
 		// see: http://stackoverflow.com/questions/13340717/json-numbers-regular-expression
 		numberMatcher := regexp.MustCompile("^ *-?(?:0|[1-9]\\d*)(?:\\.\\d+)?(?:[eE][+-]?\\d+)? *$")
 
-		if location, err := time.LoadLocation(p.Location); err != nil {
+		if format, err := NewTimestampFormat(p.Format, p.Location); err != nil {
 			return err
 		} else {
 
@@ -57,33 +56,7 @@ func (p *InfluxLineFormatProcess) Run(reader Reader, out io.Writer, errCh chan<-
 			for data := range reader.C() {
 				count++
 
-				stringTs := data.Get(p.Timestamp)
-
-				parse := func(s string) (time.Time, error) {
-					if p.Format == "ns" {
-						if ns, err := strconv.ParseInt(s, 10, 64); err != nil {
-							return time.Unix(0, 0), err
-						} else {
-							return time.Unix(0, ns), nil
-						}
-					} else if p.Format == "s" {
-						if sec, err := strconv.ParseInt(s, 10, 64); err != nil {
-							return time.Unix(0, 0), err
-						} else {
-							return time.Unix(sec, 0), nil
-						}
-					} else if p.Format == "ms" {
-						if ms, err := strconv.ParseInt(s, 10, 64); err != nil {
-							return time.Unix(0, 0), err
-						} else {
-							return time.Unix(0, ms*1000000), nil
-						}
-					} else {
-						return time.ParseInLocation(p.Format, stringTs, location)
-					}
-				}
-
-				if ts, err := parse(stringTs); err != nil {
+				if ts, err := format.Parse(data.Get(p.Timestamp)); err != nil {
 					return err
 				} else {
 
@@ -0,0 +1,254 @@
+package csv
+
+import (
+	encoding "encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// An Input describes the input of a command: a sequence of files, named explicitly or by glob
+// patterns, that are opened in turn and read as one logical stream of CSV records. Files compressed
+// with gzip, bzip2 or zlib are decompressed transparently. The headers of the files are reconciled
+// according to Mode, in the same way as a ConcatProcess.
+//
+// If SourceColumn is specified, an additional column of that name records the name of the file
+// from which each record was read. If LineColumn is specified, an additional column of that name
+// records the line of the file on which each record starts. These columns allow downstream errors
+// to be traced back to the offending input record.
+type Input struct {
+	Files        []string   // file names or glob patterns; "-" denotes stdin, which is also used if Files is empty
+	Mode         ConcatMode // the mode used to reconcile the headers of multiple files
+	SourceColumn string     // the name of an additional column that records the file name of each record
+	LineColumn   string     // the name of an additional column that records the line number of each record
+	Delimiter    rune       // the field delimiter of the input files. ',' is used if zero.
+}
+
+// Define the command line flags used to configure the receiver in the specified flag set.
+// The caller is expected to assign the remaining arguments to Files once the flags have been parsed.
+func (in *Input) AddFlags(flags *flag.FlagSet) {
+	flags.Var(&in.Mode, "header-mode", "How the headers of multiple input files are reconciled. One of: strict, union, intersection")
+	flags.StringVar(&in.SourceColumn, "source-column", "", "The name of an additional column that records the file name of each input record.")
+	flags.StringVar(&in.LineColumn, "line-column", "", "The name of an additional column that records the line number of each input record.")
+}
+
+// Open the files of the receiver and answer a Reader that yields the records of each file in turn.
+// The header of each file is read when Open is called, but the records of each file are not read
+// until the records of the preceding files have been consumed.
+func (in *Input) Open() (Reader, error) {
+	files, err := in.expand()
+	if err != nil {
+		return nil, err
+	}
+
+	readers := make([]Reader, 0, len(files))
+	for _, f := range files {
+		if r, err := in.open(f); err != nil {
+			for _, r := range readers {
+				r.Close()
+			}
+			return nil, err
+		} else {
+			readers = append(readers, r)
+		}
+	}
+
+	if len(readers) == 1 && in.SourceColumn == "" {
+		return readers[0], nil
+	}
+
+	concat := &ConcatProcess{
+		Mode:         in.Mode,
+		SourceColumn: in.SourceColumn,
+		Sources:      files,
+	}
+	if _, err := concat.header(readers); err != nil {
+		for _, r := range readers {
+			r.Close()
+		}
+		return nil, err
+	}
+
+	pipe := NewPipe()
+	go concat.Run(readers, pipe.Builder(), make(chan error, 1))
+	return pipe.Reader(), nil
+}
+
+// Expand the glob patterns of the receiver into a list of file names.
+func (in *Input) expand() ([]string, error) {
+	if len(in.Files) == 0 {
+		return []string{"-"}, nil
+	}
+	result := []string{}
+	stdin := 0
+	for _, f := range in.Files {
+		if f == "-" {
+			stdin++
+		}
+		if f == "-" || !strings.ContainsAny(f, "*?[") {
+			result = append(result, f)
+			continue
+		}
+		if matches, err := filepath.Glob(f); err != nil {
+			return nil, fmt.Errorf("%s: %v", f, err)
+		} else if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no files match", f)
+		} else {
+			result = append(result, matches...)
+		}
+	}
+	if stdin > 1 {
+		return nil, fmt.Errorf("stdin may only be specified once")
+	}
+	return result, nil
+}
+
+// Answer a new encoding/csv reader for the specified io reader.
+func (in *Input) csvReader(r io.Reader) *encoding.Reader {
+	result := encoding.NewReader(r)
+	if in.Delimiter != 0 {
+		result.Comma = in.Delimiter
+	}
+	result.FieldsPerRecord = -1
+	return result
+}
+
+// Answer a Reader for the named file whose header has been read, but whose records will
+// not be read until they are requested. The file remains open until its records have been
+// read or the Reader is closed, so that pipes and FIFOs can be read.
+func (in *Input) open(name string) (Reader, error) {
+	var file io.ReadCloser = os.Stdin
+	if name != "-" {
+		if f, err := os.Open(name); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		} else {
+			file = f
+		}
+	}
+	d, err := Decompress(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	// read the header now, so that the headers of all the files can be reconciled
+	// before any records are read.
+	csvReader := in.csvReader(d)
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		// an empty input is an empty stream, without a header
+		header, err = []string{}, nil
+		csvReader = nil
+	}
+	if err != nil {
+		d.Close()
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	lineColumn := in.LineColumn != "" && csvReader != nil
+	if lineColumn {
+		if utils.NewIndex(header).Contains(in.LineColumn) {
+			d.Close()
+			return nil, fmt.Errorf("%s: %s already exists in data header", name, in.LineColumn)
+		}
+		extend := make([]string, len(header)+1)
+		copy(extend, header)
+		extend[len(header)] = in.LineColumn
+		header = extend
+	}
+
+	return &fileReader{
+		name:       name,
+		header:     header,
+		lineColumn: lineColumn,
+		csvReader:  csvReader,
+		closer:     d,
+		ch:         make(chan Record),
+		quit:       make(chan interface{}),
+	}, nil
+}
+
+// A fileReader is a Reader for a single file whose records are not read until they are requested.
+type fileReader struct {
+	name       string
+	header     []string
+	lineColumn bool
+	csvReader  *encoding.Reader // the reader of the records of the file, or nil if the file is empty
+	closer     io.Closer
+	start      sync.Once
+	stop       sync.Once
+	ch         chan Record
+	quit       chan interface{}
+	err        error
+}
+
+func (r *fileReader) Header() []string {
+	return r.header
+}
+
+func (r *fileReader) C() <-chan Record {
+	r.start.Do(func() {
+		go r.run()
+	})
+	return r.ch
+}
+
+func (r *fileReader) Error() error {
+	return r.err
+}
+
+func (r *fileReader) Close() {
+	r.stop.Do(func() {
+		close(r.quit)
+	})
+	// release the file if its records were never requested
+	r.start.Do(func() {
+		r.closer.Close()
+		close(r.ch)
+	})
+}
+
+// Read the records of the file into the channel of the receiver.
+func (r *fileReader) run() {
+	defer close(r.ch)
+	defer func() {
+		if e := r.closer.Close(); r.err == nil {
+			r.err = e
+		}
+	}()
+
+	csvReader := r.csvReader
+	if csvReader == nil {
+		return
+	}
+
+	builder := NewRecordBuilder(r.header)
+	for {
+		fields, err := csvReader.Read()
+		if err != nil {
+			if err != io.EOF {
+				r.err = fmt.Errorf("%s: %v", r.name, err)
+			}
+			return
+		}
+		if r.lineColumn {
+			line, _ := csvReader.FieldPos(0)
+			tmp := make([]string, len(r.header))
+			copy(tmp[:len(r.header)-1], fields)
+			tmp[len(r.header)-1] = strconv.Itoa(line)
+			fields = tmp
+		}
+		select {
+		case r.ch <- builder(fields):
+		case <-r.quit:
+			return
+		}
+	}
+}
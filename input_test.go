@@ -0,0 +1,53 @@
+package csv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInputEmptyFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{"a.csv": "k,v\n1,a\n", "b.csv": "", "c.csv": "k,v\n2,b\n"}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := [][]string{{"a.csv", "b.csv", "c.csv"}, {"b.csv", "a.csv", "c.csv"}}
+	for _, names := range cases {
+		in := &Input{Mode: ConcatStrict}
+		for _, n := range names {
+			in.Files = append(in.Files, filepath.Join(dir, n))
+		}
+		reader, err := in.Open()
+		if err != nil {
+			t.Fatalf("%v: %v", names, err)
+		}
+		if Format(reader.Header()) != "k,v" {
+			t.Fatalf("%v: unexpected header: %s", names, Format(reader.Header()))
+		}
+		records, err := ReadAll(reader)
+		if err != nil {
+			t.Fatalf("%v: %v", names, err)
+		}
+		if len(records) != 2 || records[0].Get("v") != "a" || records[1].Get("v") != "b" {
+			t.Fatalf("%v: unexpected records: %v", names, records)
+		}
+	}
+
+	reader, err := (&Input{Files: []string{filepath.Join(dir, "b.csv")}}).Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records, err := ReadAll(reader); err != nil || len(reader.Header()) != 0 || len(records) != 0 {
+		t.Fatalf("expected an empty stream, got %v, %v", records, err)
+	}
+}
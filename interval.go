@@ -0,0 +1,195 @@
+package csv
+
+import (
+	"fmt"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// Interval specifies an interval join. Rather than matching the values of columns of each stream
+// exactly, an interval join matches each left record with every right record that has equal keys and
+// whose half-open range [RightStart, RightEnd) contains the value of the left record's LeftKey column.
+// The intervals of the right records may overlap, in which case a left record matches several right
+// records.
+//
+// The values are numbers or, if Format is specified, timestamps in the specified format (per
+// TimestampFormat).
+//
+// The left stream must be sorted by the join keys and then by LeftKey. The right stream must be sorted by
+// the join keys and then by RightStart. The streams are swept one record at a time, so only the right
+// records whose intervals may contain the values of subsequent left records are held in memory. Unmatched
+// left records are copied if LeftOuter is specified and unmatched right records are copied, once their
+// intervals have been passed, if RightOuter is specified.
+type Interval struct {
+	LeftKey    string // the column of the left stream that contains the value
+	RightStart string // the column of the right stream that contains the inclusive start of the interval
+	RightEnd   string // the column of the right stream that contains the exclusive end of the interval
+	Format     string // the format of timestamp values. The values are numbers if empty.
+	Location   string // the location in which timestamps are interpreted (per go time.LoadLocation())
+}
+
+// A right record whose interval may contain the values of subsequent left records.
+type activeInterval struct {
+	record     Record
+	key        []string
+	start, end orderValue
+	matched    bool
+}
+
+func (p *Join) runInterval(left Reader, right Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer left.Close()
+		defer right.Close()
+
+		iv := p.Interval
+
+		if _, x, _ := utils.Intersect(append(append([]string{}, p.LeftKeys...), iv.LeftKey), left.Header()); len(x) != 0 {
+			return fmt.Errorf("left: %s does not exist in the data header", Format(x))
+		}
+		if _, x, _ := utils.Intersect(append(append([]string{}, p.RightKeys...), iv.RightStart, iv.RightEnd), right.Header()); len(x) != 0 {
+			return fmt.Errorf("right: %s does not exist in the data header", Format(x))
+		}
+
+		order, err := newOrdering(iv.Format, iv.Location)
+		if err != nil {
+			return err
+		}
+
+		compareKeys := compareWith(p.less())
+		leftKey := (&SortKeys{Keys: p.LeftKeys}).AsStringProjection()
+		rightKey := (&SortKeys{Keys: p.RightKeys}).AsStringProjection()
+
+		leftBlank := NewRecordBuilder(left.Header())([]string{})
+		rightBlank := NewRecordBuilder(right.Header())([]string{})
+
+		keyHeader, leftHeader, rightHeader := p.headers(left.Header(), right.Header())
+		outputHeader := p.outputHeader(keyHeader, leftHeader, rightHeader)
+		if err = checkDuplicateColumns(outputHeader); err != nil {
+			return err
+		}
+		writer := builder(outputHeader)
+		defer writer.Close(err)
+
+		record := p.recordWriter(writer, keyHeader, leftHeader, rightHeader)
+		write := func(k []string, l, r Record) error {
+			return writer.Write(record(k, l, r))
+		}
+
+		// release a right record whose interval has been passed
+		release := func(a *activeInterval) error {
+			if a.matched || !p.RightOuter {
+				return nil
+			}
+			return write(a.key, leftBlank, a.record)
+		}
+
+		// next is the first right record that has not been consumed
+		var next *activeInterval
+		advance := func() error {
+			r := <-right.C()
+			if r == nil {
+				next = nil
+				return nil
+			}
+			a := &activeInterval{record: r, key: rightKey(r)}
+			var err error
+			if a.start, err = order.parse(r.Get(iv.RightStart)); err != nil {
+				return fmt.Errorf("right: %v", err)
+			}
+			if a.end, err = order.parse(r.Get(iv.RightEnd)); err != nil {
+				return fmt.Errorf("right: %v", err)
+			}
+			if next != nil {
+				if c := compareKeys(a.key, next.key); c < 0 || (c == 0 && order.compare(a.start, next.start) < 0) {
+					return fmt.Errorf("right: records are not sorted: %s follows %s", Format(r.AsSlice()), Format(next.record.AsSlice()))
+				}
+			}
+			next = a
+			return nil
+		}
+
+		if err = advance(); err != nil {
+			return err
+		}
+
+		active := []*activeInterval{}
+		var last Record
+		var lastKey []string
+		var lastValue orderValue
+		for l := range left.C() {
+			k := leftKey(l)
+			v, err := order.parse(l.Get(iv.LeftKey))
+			if err != nil {
+				return fmt.Errorf("left: %v", err)
+			}
+			if last != nil {
+				if c := compareKeys(k, lastKey); c < 0 || (c == 0 && order.compare(v, lastValue) < 0) {
+					return fmt.Errorf("left: records are not sorted: %s follows %s", Format(l.AsSlice()), Format(last.AsSlice()))
+				}
+			}
+			last, lastKey, lastValue = l, k, v
+
+			// release the intervals that end at or before the value, including those of preceding keys
+			retained := active[:0]
+			for _, a := range active {
+				if compareKeys(a.key, k) == 0 && order.compare(v, a.end) < 0 {
+					retained = append(retained, a)
+				} else if err := release(a); err != nil {
+					return err
+				}
+			}
+			active = retained
+
+			// consume the right records of preceding keys and the intervals that start at or before the value
+			for next != nil {
+				c := compareKeys(next.key, k)
+				if c > 0 || (c == 0 && order.compare(next.start, v) > 0) {
+					break
+				}
+				if c == 0 && order.compare(v, next.end) < 0 {
+					active = append(active, next)
+				} else if err := release(next); err != nil {
+					return err
+				}
+				if err := advance(); err != nil {
+					return err
+				}
+			}
+
+			if len(active) == 0 {
+				if p.LeftOuter {
+					if err := write(k, l, rightBlank); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			for _, a := range active {
+				a.matched = true
+				if err := write(k, l, a.record); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err = left.Error(); err != nil {
+			return err
+		}
+
+		// release the remaining right records
+		for _, a := range active {
+			if err = release(a); err != nil {
+				return err
+			}
+		}
+		for next != nil {
+			if err = release(next); err != nil {
+				return err
+			}
+			if err = advance(); err != nil {
+				return err
+			}
+		}
+		return right.Error()
+	}()
+}
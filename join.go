@@ -1,17 +1,57 @@
 package csv
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/wildducktheories/go-csv/utils"
 )
 
 // A Join can be used to construct a process that will join two streams of CSV records by matching
 // records from each stream on the specified key columns.
+//
+// The names of the non-key columns of each stream are prefixed in the output stream by LeftPrefix and
+// RightPrefix. It is an error for the output header to contain the same column twice.
 type Join struct {
-	LeftKeys   []string // the names of the keys from the left stream
-	RightKeys  []string // the names of the keys from the right stream
-	Numeric    []string // the names of the keys in the left stream that are numeric keys
-	LeftOuter  bool     // perform a left outer join - left rows are copied even if there is no matching right row
-	RightOuter bool     // perform a right outer join - right rows are copied even if there is no matching left row
+	LeftKeys    []string                    // the names of the keys from the left stream
+	RightKeys   []string                    // the names of the keys from the right stream
+	Numeric     []string                    // the names of the keys in the left stream that are numeric keys
+	Decimal     []string                    // the names of the keys in the left stream that are exact decimal keys
+	Dates       map[string]string           // maps the names of keys in the left stream to the layouts of the timestamps they contain
+	Location    *time.Location              // the location in which timestamp keys are interpreted. UTC if nil.
+	Comparators map[string]StringComparator // maps the names of keys in the left stream to the comparators used to compare them
+	LeftOuter   bool                        // perform a left outer join - left rows are copied even if there is no matching right row
+	RightOuter  bool                        // perform a right outer join - right rows are copied even if there is no matching left row
+	AsOf        *AsOf                       // if specified, perform an as-of join on the specified ordering keys
+	Interval    *Interval                   // if specified, perform an interval join on the specified value and range columns
+	Fuzzy       *Fuzzy                      // if specified, perform a fuzzy join on the specified text columns
+	LeftPrefix  string                      // a prefix for the names of the non-key columns of the left stream in the output stream
+	RightPrefix string                      // a prefix for the names of the non-key columns of the right stream in the output stream
+}
+
+// A DuplicateColumnsError is answered by a join whose output header would contain the same column
+// more than once. The columns of each input can be distinguished by giving each input a prefix.
+type DuplicateColumnsError struct {
+	Columns []string // the columns that occur more than once
+}
+
+func (e *DuplicateColumnsError) Error() string {
+	return fmt.Sprintf("output header contains duplicate columns: %s", Format(e.Columns))
+}
+
+// Answer a DuplicateColumnsError if the specified header contains the same column more than once.
+func checkDuplicateColumns(header []string) error {
+	count := map[string]int{}
+	duplicates := []string{}
+	for _, h := range header {
+		if count[h]++; count[h] == 2 {
+			duplicates = append(duplicates, h)
+		}
+	}
+	if len(duplicates) > 0 {
+		return &DuplicateColumnsError{Columns: duplicates}
+	}
+	return nil
 }
 
 // A decorator for a reader that returns groups of consecutive records from the underlying reader
@@ -71,26 +111,66 @@ func (g *groupReader) get() []Record {
 // Construct a key comparison function for key values
 func (p *Join) less() StringSliceComparator {
 	return (&SortKeys{
-		Keys:    p.LeftKeys,
-		Numeric: p.Numeric,
+		Keys:        p.LeftKeys,
+		Numeric:     p.Numeric,
+		Decimal:     p.Decimal,
+		Dates:       p.Dates,
+		Location:    p.Location,
+		Comparators: p.Comparators,
 	}).AsStringSliceComparator()
 }
 
-// split the headers into the set of all headers, the set of key headers, the set of left headers
-// and the set of right headers
-func (p *Join) headers(leftHeader []string, rightHeader []string) ([]string, []string, []string, []string) {
+// split the headers into the set of key headers, the set of left headers and the set of right headers
+func (p *Join) headers(leftHeader []string, rightHeader []string) ([]string, []string, []string) {
 	i, a, _ := utils.Intersect(leftHeader, p.LeftKeys)
 	_, b, _ := utils.Intersect(rightHeader, p.RightKeys)
-	f := make([]string, len(i)+len(a)+len(b))
+	return i, a, b
+}
 
-	copy(f, i)
-	copy(f[len(i):], a)
-	copy(f[len(i)+len(a):], b)
+// Answer the output header that contains the specified key headers followed by the specified left
+// and right headers, with the prefixes of the receiver applied.
+func (p *Join) outputHeader(keyHeader []string, leftHeader []string, rightHeader []string) []string {
+	f := make([]string, 0, len(keyHeader)+len(leftHeader)+len(rightHeader))
+	f = append(f, keyHeader...)
+	for _, h := range leftHeader {
+		f = append(f, p.LeftPrefix+h)
+	}
+	for _, h := range rightHeader {
+		f = append(f, p.RightPrefix+h)
+	}
+	return f
+}
 
-	return f, i, a, b
+// Answer a function that writes a record that contains the specified key and the non-key values of
+// the specified left and right records to the specified writer.
+func (p *Join) recordWriter(writer Writer, keyHeader []string, leftHeader []string, rightHeader []string) func(k []string, l, r Record) Record {
+	return func(k []string, l, r Record) Record {
+		o := writer.Blank()
+		for i, h := range keyHeader {
+			o.Put(h, k[i])
+		}
+		for _, h := range leftHeader {
+			o.Put(p.LeftPrefix+h, l.Get(h))
+		}
+		for _, h := range rightHeader {
+			o.Put(p.RightPrefix+h, r.Get(h))
+		}
+		return o
+	}
 }
 
 func (p *Join) run(left Reader, right Reader, builder WriterBuilder, errCh chan<- error) {
+	if p.AsOf != nil {
+		p.runAsOf(left, right, builder, errCh)
+		return
+	} else if p.Interval != nil {
+		p.runInterval(left, right, builder, errCh)
+		return
+	} else if p.Fuzzy != nil {
+		p.runFuzzy(left, right, builder, errCh)
+		return
+	}
+
 	errCh <- func() (err error) {
 		defer left.Close()
 		defer right.Close()
@@ -100,25 +180,20 @@ func (p *Join) run(left Reader, right Reader, builder WriterBuilder, errCh chan<
 		leftBlank := NewRecordBuilder(left.Header())([]string{})
 		rightBlank := NewRecordBuilder(right.Header())([]string{})
 
-		outputHeader, keyHeader, leftHeader, rightHeader := p.headers(left.Header(), right.Header())
+		keyHeader, leftHeader, rightHeader := p.headers(left.Header(), right.Header())
+		outputHeader := p.outputHeader(keyHeader, leftHeader, rightHeader)
+		if err = checkDuplicateColumns(outputHeader); err != nil {
+			return err
+		}
 		writer := builder(outputHeader)
 		defer writer.Close(err)
 
+		record := p.recordWriter(writer, keyHeader, leftHeader, rightHeader)
 		leftG := &groupReader{reader: left, less: less, tokey: (&SortKeys{Keys: p.LeftKeys}).AsStringProjection()}
 		rightG := &groupReader{reader: right, less: less, tokey: (&SortKeys{Keys: p.RightKeys}).AsStringProjection()}
 
 		w := func(k []string, l, r Record) error {
-			o := writer.Blank()
-			for i, h := range keyHeader {
-				o.Put(h, k[i])
-			}
-			for _, h := range leftHeader {
-				o.Put(h, l.Get(h))
-			}
-			for _, h := range rightHeader {
-				o.Put(h, r.Get(h))
-			}
-			return writer.Write(o)
+			return writer.Write(record(k, l, r))
 		}
 
 		for leftG.hasNext() && rightG.hasNext() {
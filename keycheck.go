@@ -0,0 +1,208 @@
+package csv
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// Given a header-prefixed input stream of CSV records, KeyCheckProcess verifies that the primary key
+// (Keys) of each record is non-empty and unique and, if a Parent stream is specified, that the foreign key
+// (ForeignKeys) of each record matches the key (ParentKeys) of some record of the Parent stream.
+//
+// The output stream is a report with one record for each violation, containing the kind of violation
+// (empty, duplicate or orphan), the offending key and the line numbers of the records with that key,
+// separated by spaces. A primary key is empty if any of its columns is empty. A foreign key with an empty
+// column is treated as null and is not checked against the Parent stream.
+//
+// The line numbers are taken from LineColumn, which is typically added by Input. If LineColumn is empty,
+// the records are numbered by their position in the input stream, counting the header as line 1.
+//
+// Each check sorts a copy of the input stream by the relevant key. The foreign key check also sorts the
+// Parent stream and merges the sorted streams. If there are any violations, the process completes with
+// an error after the report has been written.
+type KeyCheckProcess struct {
+	Keys        []string // the columns of the primary key. Not checked if empty.
+	ForeignKeys []string // the columns of the foreign key
+	Parent      Reader   // the stream referenced by the foreign key. Not checked if nil.
+	ParentKeys  []string // the columns of the Parent stream referenced by the foreign key
+	LineColumn  string   // the column that contains the line number of each record
+}
+
+// The header of the report generated by a KeyCheckProcess.
+var KeyCheckHeader = []string{"violation", "key", "lines"}
+
+// A process that appends the line number of each record, counting the header as line 1.
+type lineNumberProcess struct {
+	column string
+}
+
+func (p *lineNumberProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+		augmentedHeader := make([]string, len(dataHeader)+1)
+		copy(augmentedHeader, dataHeader)
+		augmentedHeader[len(dataHeader)] = p.column
+
+		writer := builder(augmentedHeader)
+		defer writer.Close(err)
+
+		line := 1
+		for data := range reader.C() {
+			line++
+			augmentedData := writer.Blank()
+			augmentedData.PutAll(data)
+			augmentedData.Put(p.column, strconv.Itoa(line))
+			if err := writer.Write(augmentedData); err != nil {
+				return err
+			}
+		}
+		return reader.Error()
+	}()
+}
+
+// Answer the line numbers of the specified records, in ascending order, separated by spaces.
+func (p *KeyCheckProcess) lines(group []Record, column string) string {
+	lines := make([]string, len(group))
+	for i, r := range group {
+		lines[i] = r.Get(column)
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return LessNumericStrings(lines[i], lines[j])
+	})
+	return strings.Join(lines, " ")
+}
+
+// Answer true if any of the specified values is empty.
+func anyEmpty(values []string) bool {
+	for _, v := range values {
+		if v == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *KeyCheckProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+		if p.Parent != nil {
+			defer p.Parent.Close()
+		}
+
+		dataHeader := reader.Header()
+		if _, x, _ := utils.Intersect(append(append([]string{}, p.Keys...), p.ForeignKeys...), dataHeader); len(x) != 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+		if p.Parent != nil {
+			if len(p.ForeignKeys) == 0 || len(p.ForeignKeys) != len(p.ParentKeys) {
+				return fmt.Errorf("the foreign key and the parent key must have the same, non-zero, number of columns")
+			}
+			if _, x, _ := utils.Intersect(p.ParentKeys, p.Parent.Header()); len(x) != 0 {
+				return fmt.Errorf("parent: %s does not exist in the data header", Format(x))
+			}
+		}
+
+		// number the records, if necessary
+		lineColumn := p.LineColumn
+		if lineColumn == "" {
+			lineColumn = "line"
+			for i := 1; utils.NewIndex(dataHeader).Contains(lineColumn); i++ {
+				lineColumn = fmt.Sprintf("line_%d", i)
+			}
+			reader = WithProcess(reader, &lineNumberProcess{column: lineColumn})
+		} else if !utils.NewIndex(dataHeader).Contains(lineColumn) {
+			return fmt.Errorf("%s does not exist in the data header", lineColumn)
+		}
+
+		var primary, foreign Reader
+		switch {
+		case len(p.Keys) > 0 && p.Parent != nil:
+			copies := NewTee(reader, 2)
+			primary, foreign = copies[0], copies[1]
+		case len(p.Keys) > 0:
+			primary = reader
+		case p.Parent != nil:
+			foreign = reader
+		}
+
+		writer := builder(KeyCheckHeader)
+		defer writer.Close(err)
+
+		violations := 0
+		report := func(violation string, key []string, group []Record) error {
+			violations++
+			o := writer.Blank()
+			o.Put("violation", violation)
+			o.Put("key", Format(key))
+			o.Put("lines", p.lines(group, lineColumn))
+			return writer.Write(o)
+		}
+
+		// the foreign key check runs concurrently with the primary key check
+		var foreignG, parentG *groupReader
+		if foreign != nil {
+			foreignKeys := &SortKeys{Keys: p.ForeignKeys}
+			parentKeys := &SortKeys{Keys: p.ParentKeys}
+			less := foreignKeys.AsStringSliceComparator()
+			foreignG = &groupReader{reader: WithProcess(foreign, foreignKeys.AsSortProcess()), less: less, tokey: foreignKeys.AsStringProjection()}
+			parentG = &groupReader{reader: WithProcess(p.Parent, parentKeys.AsSortProcess()), less: less, tokey: parentKeys.AsStringProjection()}
+			defer foreignG.reader.Close()
+			defer parentG.reader.Close()
+		}
+
+		if primary != nil {
+			keys := &SortKeys{Keys: p.Keys}
+			g := &groupReader{reader: WithProcess(primary, keys.AsSortProcess()), less: keys.AsStringSliceComparator(), tokey: keys.AsStringProjection()}
+			defer g.reader.Close()
+			for g.hasNext() {
+				group := g.get()
+				if anyEmpty(g.key) {
+					err = report("empty", g.key, group)
+				} else if len(group) > 1 {
+					err = report("duplicate", g.key, group)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			if err = g.reader.Error(); err != nil {
+				return err
+			}
+		}
+
+		if foreign != nil {
+			less := foreignG.less
+			for foreignG.hasNext() {
+				for parentG.hasNext() && less(parentG.key, foreignG.key) {
+					parentG.get()
+				}
+				group := foreignG.get()
+				if anyEmpty(foreignG.key) {
+					continue
+				}
+				if !parentG.hasNext() || less(foreignG.key, parentG.key) {
+					if err = report("orphan", foreignG.key, group); err != nil {
+						return err
+					}
+				}
+			}
+			if err = foreignG.reader.Error(); err != nil {
+				return err
+			}
+			if err = parentG.reader.Error(); err != nil {
+				return err
+			}
+		}
+
+		if violations > 0 {
+			return fmt.Errorf("found %d key violations", violations)
+		}
+		return nil
+	}()
+}
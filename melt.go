@@ -0,0 +1,85 @@
+package csv
+
+import (
+	"fmt"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// Given a header-prefixed input stream of CSV records, a set of identifying columns (IdKeys) and a set
+// of value columns (ValueKeys), MeltProcess converts each input record into one output record for each
+// value column. Each output record contains the identifying columns of the input record, the name of the
+// value column (in VariableColumn) and the value of that column (in ValueColumn).
+//
+// If ValueKeys is empty, every column that is not an identifying column is treated as a value column.
+// Columns that are neither identifying columns nor value columns are discarded. If SkipEmpty is true,
+// no output record is generated for an empty value.
+type MeltProcess struct {
+	IdKeys         []string // the columns that identify each input record
+	ValueKeys      []string // the columns whose values are converted into records
+	VariableColumn string   // the name of the output column that contains the name of the value column. "variable" if empty.
+	ValueColumn    string   // the name of the output column that contains the value. "value" if empty.
+	SkipEmpty      bool     // do not generate records for empty values
+}
+
+// Answer the names of the variable and value columns.
+func (p *MeltProcess) columns() (string, string) {
+	variable, value := p.VariableColumn, p.ValueColumn
+	if variable == "" {
+		variable = "variable"
+	}
+	if value == "" {
+		value = "value"
+	}
+	return variable, value
+}
+
+func (p *MeltProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+		variableColumn, valueColumn := p.columns()
+
+		if _, x, _ := utils.Intersect(p.IdKeys, dataHeader); len(x) != 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+
+		valueKeys := p.ValueKeys
+		if len(valueKeys) == 0 {
+			_, _, valueKeys = utils.Intersect(p.IdKeys, dataHeader)
+		} else if _, x, _ := utils.Intersect(valueKeys, dataHeader); len(x) != 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+
+		outputHeader := make([]string, len(p.IdKeys), len(p.IdKeys)+2)
+		copy(outputHeader, p.IdKeys)
+		outputHeader = append(outputHeader, variableColumn, valueColumn)
+		if len(utils.NewIndex(outputHeader)) != len(outputHeader) {
+			return fmt.Errorf("output header contains duplicate columns: %s", Format(outputHeader))
+		}
+
+		writer := builder(outputHeader)
+		defer writer.Close(err)
+
+		for data := range reader.C() {
+			for _, k := range valueKeys {
+				v := data.Get(k)
+				if v == "" && p.SkipEmpty {
+					continue
+				}
+				o := writer.Blank()
+				for _, id := range p.IdKeys {
+					o.Put(id, data.Get(id))
+				}
+				o.Put(variableColumn, k)
+				o.Put(valueColumn, v)
+				if err = writer.Write(o); err != nil {
+					return err
+				}
+			}
+		}
+
+		return reader.Error()
+	}()
+}
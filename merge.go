@@ -0,0 +1,158 @@
+package csv
+
+import (
+	"container/heap"
+	"fmt"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// A MergeProcess merges several streams of CSV records, each of which is already sorted
+// according to SortKeys, into a single stream that is sorted according to the same keys.
+//
+// The header of the output stream is the union of the headers of the input streams, in order
+// of first appearance. Records are aligned with the output header by column name and any
+// column that is missing from an input stream is left empty. It is an error for an input stream
+// to be missing one of the sort keys or to contain the same column twice.
+//
+// Records with equal keys are emitted in the order of the input streams. If Dedup is true, only
+// the first of each sequence of records with equal keys is emitted.
+type MergeProcess struct {
+	SortKeys SortKeys // the keys by which each input stream is sorted
+	Dedup    bool     // emit only the first record for each distinct key
+}
+
+type mergeItem struct {
+	record Record
+	input  int
+}
+
+type mergeHeap struct {
+	items []mergeItem
+	less  RecordComparator
+}
+
+func (h *mergeHeap) Len() int {
+	return len(h.items)
+}
+
+func (h *mergeHeap) Less(i, j int) bool {
+	l, r := h.items[i], h.items[j]
+	if h.less(l.record, r.record) {
+		return true
+	} else if h.less(r.record, l.record) {
+		return false
+	}
+	return l.input < r.input
+}
+
+func (h *mergeHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *mergeHeap) Push(x interface{}) {
+	h.items = append(h.items, x.(mergeItem))
+}
+
+func (h *mergeHeap) Pop() interface{} {
+	n := len(h.items)
+	x := h.items[n-1]
+	h.items = h.items[:n-1]
+	return x
+}
+
+// Answer the header that results from reconciling the headers of the specified readers.
+func (p *MergeProcess) header(readers []Reader) ([]string, error) {
+	result := []string{}
+	for i, r := range readers {
+		h := r.Header()
+		if len(utils.NewIndex(h)) != len(h) {
+			return nil, fmt.Errorf("input %d: header contains duplicate columns: %s", i, Format(h))
+		}
+		if _, x, _ := utils.Intersect(p.SortKeys.Keys, h); len(x) != 0 {
+			return nil, fmt.Errorf("input %d: missing keys: %s", i, Format(x))
+		}
+		result = utils.Union(result, h)
+	}
+	return result, nil
+}
+
+// Run the merge process against the specified readers, writing the merged stream to a Writer
+// constructed from the specified builder.
+func (p *MergeProcess) Run(readers []Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		for _, r := range readers {
+			defer r.Close()
+		}
+
+		header, err := p.header(readers)
+		if err != nil {
+			return err
+		}
+
+		writer := builder(header)
+		defer writer.Close(err)
+
+		less := p.SortKeys.AsRecordComparator()
+		h := &mergeHeap{
+			items: make([]mergeItem, 0, len(readers)),
+			less:  less,
+		}
+
+		for i, r := range readers {
+			if rec, ok := <-r.C(); ok {
+				h.items = append(h.items, mergeItem{record: rec, input: i})
+			}
+		}
+		heap.Init(h)
+
+		var last Record
+		for h.Len() > 0 {
+			item := h.items[0]
+
+			if !p.Dedup || last == nil || less(last, item.record) || less(item.record, last) {
+				o := writer.Blank()
+				o.PutAll(item.record)
+				if err := writer.Write(o); err != nil {
+					return err
+				}
+			}
+			last = item.record
+
+			if next, ok := <-readers[item.input].C(); ok {
+				if less(next, item.record) {
+					return fmt.Errorf("input %d: records are not sorted: %s follows %s", item.input, Format(next.AsSlice()), Format(item.record.AsSlice()))
+				}
+				h.items[0] = mergeItem{record: next, input: item.input}
+				heap.Fix(h, 0)
+			} else {
+				heap.Pop(h)
+			}
+		}
+
+		for _, r := range readers {
+			if err := r.Error(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+}
+
+type mergeProcess struct {
+	merge  *MergeProcess
+	others []Reader
+}
+
+// Binds the specified readers as additional inputs of the merge and returns a Process
+// whose reader will be considered as the first input of the merge.
+func (p *MergeProcess) WithOthers(others []Reader) Process {
+	return &mergeProcess{
+		merge:  p,
+		others: others,
+	}
+}
+
+func (m *mergeProcess) Run(r Reader, builder WriterBuilder, errCh chan<- error) {
+	m.merge.Run(append([]Reader{r}, m.others...), builder, errCh)
+}
@@ -0,0 +1,46 @@
+package csv
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type bufferCloser struct{ *bytes.Buffer }
+
+func (bufferCloser) Close() error { return nil }
+
+// Answer a reader of the specified CSV data.
+func stringReader(data string) Reader {
+	return WithIoReader(ioutil.NopCloser(strings.NewReader(data)))
+}
+
+func TestMergeReversedKeys(t *testing.T) {
+	cases := []struct {
+		dedup    bool
+		expected string
+	}{
+		{false, "k,v\n3,a\n3,b\n3,c\n2,d\n1,e\n"},
+		{true, "k,v\n3,a\n2,d\n1,e\n"},
+	}
+	for _, c := range cases {
+		p := &MergeProcess{
+			SortKeys: SortKeys{Keys: []string{"k"}, Numeric: []string{"k"}, Reversed: []string{"k"}},
+			Dedup:    c.dedup,
+		}
+		readers := []Reader{
+			stringReader("k,v\n3,a\n3,b\n1,e\n"),
+			stringReader("k,v\n3,c\n2,d\n"),
+		}
+		var buf bytes.Buffer
+		errCh := make(chan error, 1)
+		p.Run(readers, WithIoWriter(bufferCloser{&buf}), errCh)
+		if err := <-errCh; err != nil {
+			t.Fatalf("dedup %v: %v", c.dedup, err)
+		}
+		if buf.String() != c.expected {
+			t.Fatalf("dedup %v: expected:\n%s\ngot:\n%s", c.dedup, c.expected, buf.String())
+		}
+	}
+}
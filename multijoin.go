@@ -0,0 +1,174 @@
+package csv
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// A JoinInput specifies one of the input streams of a MultiJoin.
+type JoinInput struct {
+	Reader Reader   // the input stream, which must be sorted by Keys
+	Keys   []string // the names of the key columns of this input, in the order of MultiJoin.Keys
+	Outer  bool     // copy the records of this input even if some other input has no matching record
+	Prefix string   // a prefix for the names of the non-key columns of this input in the output stream
+}
+
+// A MultiJoin joins any number of streams of CSV records, each of which is sorted by its key columns,
+// in a single pass. The key columns of each input are mapped, by position, to a common key whose
+// columns are named by Keys.
+//
+// For each distinct key, the output stream contains the product of the records of each input with
+// that key. An input without such records contributes a single record with empty values. The records
+// with a key are copied only if every input has a record with the key or if at least one of the inputs
+// with such a record is Outer. So, if no input is Outer, the result is an inner join and if every
+// input is Outer, the result is a full outer join.
+//
+// The header of the output stream contains the key columns followed by the non-key columns of each input,
+// in order, each prefixed by the input's Prefix. It is an error for the output header to contain the same
+// column twice.
+type MultiJoin struct {
+	Keys        []string                    // the names of the key columns in the output stream
+	Numeric     []string                    // the names of the key columns that are compared numerically
+	Decimal     []string                    // the names of the key columns that are compared as exact decimals
+	Dates       map[string]string           // maps the names of key columns to the layouts of the timestamps they contain
+	Location    *time.Location              // the location in which timestamp keys are interpreted. UTC if nil.
+	Comparators map[string]StringComparator // maps the names of key columns to the comparators used to compare them
+	Inputs      []JoinInput                 // the input streams
+}
+
+// Answer the output header and the non-key columns of each input.
+func (p *MultiJoin) headers() ([]string, [][]string, error) {
+	header := make([]string, len(p.Keys))
+	copy(header, p.Keys)
+	columns := make([][]string, len(p.Inputs))
+	for i, in := range p.Inputs {
+		h := in.Reader.Header()
+		if len(in.Keys) != len(p.Keys) {
+			return nil, nil, fmt.Errorf("input %d: expected %d keys, found %d", i, len(p.Keys), len(in.Keys))
+		}
+		if _, x, _ := utils.Intersect(in.Keys, h); len(x) != 0 {
+			return nil, nil, fmt.Errorf("input %d: %s does not exist in the data header", i, Format(x))
+		}
+		_, _, columns[i] = utils.Intersect(in.Keys, h)
+		for _, c := range columns[i] {
+			header = append(header, in.Prefix+c)
+		}
+	}
+	if err := checkDuplicateColumns(header); err != nil {
+		return nil, nil, err
+	}
+	return header, columns, nil
+}
+
+// Run the join against the receiver's inputs, writing the joined stream to a Writer constructed from
+// the specified builder.
+func (p *MultiJoin) Run(builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		for _, in := range p.Inputs {
+			defer in.Reader.Close()
+		}
+
+		header, columns, err := p.headers()
+		if err != nil {
+			return err
+		}
+
+		writer := builder(header)
+		defer writer.Close(err)
+
+		less := (&SortKeys{
+			Keys:        p.Keys,
+			Numeric:     p.Numeric,
+			Decimal:     p.Decimal,
+			Dates:       p.Dates,
+			Location:    p.Location,
+			Comparators: p.Comparators,
+		}).AsStringSliceComparator()
+
+		n := len(p.Inputs)
+		readers := make([]*groupReader, n)
+		blanks := make([]Record, n)
+		for i, in := range p.Inputs {
+			readers[i] = &groupReader{reader: in.Reader, less: less, tokey: (&SortKeys{Keys: in.Keys}).AsStringProjection()}
+			blanks[i] = NewRecordBuilder(in.Reader.Header())([]string{})
+		}
+
+		last := make([][]string, n)
+		groups := make([][]Record, n)
+		cursor := make([]int, n)
+		for {
+			// find the least key of the next group of each input
+			var key []string
+			for _, g := range readers {
+				if g.hasNext() && (key == nil || less(g.key, key)) {
+					key = g.key
+				}
+			}
+			if key == nil {
+				break
+			}
+
+			present := 0
+			outer := false
+			for i, g := range readers {
+				groups[i] = nil
+				if !g.hasNext() || less(key, g.key) {
+					continue
+				}
+				if last[i] != nil && less(g.key, last[i]) {
+					return fmt.Errorf("input %d: records are not sorted: %s follows %s", i, Format(g.key), Format(last[i]))
+				}
+				last[i] = g.key
+				groups[i] = g.get()
+				present++
+				outer = outer || p.Inputs[i].Outer
+			}
+
+			if present < n && !outer {
+				continue
+			}
+
+			// write the product of the groups
+			for i := range cursor {
+				cursor[i] = 0
+			}
+			for done := false; !done; {
+				o := writer.Blank()
+				for i, k := range p.Keys {
+					o.Put(k, key[i])
+				}
+				for i, in := range p.Inputs {
+					r := blanks[i]
+					if groups[i] != nil {
+						r = groups[i][cursor[i]]
+					}
+					for _, c := range columns[i] {
+						o.Put(in.Prefix+c, r.Get(c))
+					}
+				}
+				if err = writer.Write(o); err != nil {
+					return err
+				}
+
+				done = true
+				for i := n - 1; i >= 0; i-- {
+					if cursor[i]+1 < len(groups[i]) {
+						cursor[i]++
+						done = false
+						break
+					}
+					cursor[i] = 0
+				}
+			}
+		}
+
+		for _, in := range p.Inputs {
+			if err = in.Reader.Error(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+}
@@ -0,0 +1,92 @@
+package csv
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// The parsed value of a numeric or timestamp column.
+type orderValue struct {
+	f float64
+	t time.Time
+}
+
+// An ordering parses, compares and measures the distance between the values of a column that contains
+// either numbers or, if a format is specified, timestamps.
+type ordering struct {
+	format *TimestampFormat
+}
+
+// Answer an ordering for timestamps of the specified format and location or, if format is empty, for numbers.
+func newOrdering(format string, location string) (*ordering, error) {
+	result := &ordering{}
+	if format != "" {
+		var err error
+		if result.format, err = NewTimestampFormat(format, location); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (o *ordering) parse(s string) (orderValue, error) {
+	if o.format != nil {
+		t, err := o.format.Parse(s)
+		return orderValue{t: t}, err
+	}
+	f, err := parseFloat(s)
+	if err != nil {
+		err = fmt.Errorf("not a number: %s", s)
+	}
+	return orderValue{f: f}, err
+}
+
+// Parse a distance between two values: a duration (per time.ParseDuration) for timestamps and a number otherwise.
+func (o *ordering) parseDistance(s string) (float64, error) {
+	if o.format != nil {
+		d, err := time.ParseDuration(s)
+		return float64(d), err
+	}
+	return parseFloat(s)
+}
+
+// Answer a negative number, zero or a positive number according to whether l is less than, equal to or
+// greater than r.
+func (o *ordering) compare(l, r orderValue) int {
+	if o.format != nil {
+		if l.t.Before(r.t) {
+			return -1
+		} else if l.t.After(r.t) {
+			return 1
+		}
+		return 0
+	}
+	if l.f < r.f {
+		return -1
+	} else if l.f > r.f {
+		return 1
+	}
+	return 0
+}
+
+// Answer the absolute difference between l and r, in nanoseconds for timestamps.
+func (o *ordering) distance(l, r orderValue) float64 {
+	if o.format != nil {
+		return math.Abs(float64(l.t.Sub(r.t)))
+	}
+	return math.Abs(l.f - r.f)
+}
+
+// Answer a function that compares two keys with the specified comparator, answering a negative number,
+// zero or a positive number according to whether l is less than, equal to or greater than r.
+func compareWith(less StringSliceComparator) func(l, r []string) int {
+	return func(l, r []string) int {
+		if less(l, r) {
+			return -1
+		} else if less(r, l) {
+			return 1
+		}
+		return 0
+	}
+}
@@ -0,0 +1,62 @@
+package csv
+
+import (
+	"compress/gzip"
+	"flag"
+	"io"
+	"os"
+	"strings"
+)
+
+// An Output describes the output of a command: a file, or stdout, to which a stream is written,
+// optionally compressed with gzip.
+type Output struct {
+	File      string // the name of the output file; stdout is used if empty or "-"
+	Gzip      bool   // compress the output with gzip. Implied if File ends with .gz
+	GzipLevel *int   // the gzip compression level (per compress/gzip). The default compression level is used if nil.
+	Delimiter rune   // the field delimiter of the output stream. ',' is used if zero.
+}
+
+// Define the command line flags used to configure the receiver in the specified flag set.
+func (o *Output) AddFlags(flags *flag.FlagSet) {
+	flags.StringVar(&o.File, "output", "-", "The file to which the output is written. Defaults to stdout.")
+	flags.BoolVar(&o.Gzip, "gzip", false, "Compress the output with gzip. Implied if the output file ends with .gz")
+	o.GzipLevel = new(int)
+	flags.IntVar(o.GzipLevel, "gzip-level", gzip.DefaultCompression, "The gzip compression level, from 0 (none) to 9 (best). The default compression level is used if not specified.")
+}
+
+// Open the output stream described by the receiver.
+func (o *Output) Open() (io.WriteCloser, error) {
+	var w io.WriteCloser = os.Stdout
+	if o.File != "" && o.File != "-" {
+		if f, err := os.Create(o.File); err != nil {
+			return nil, err
+		} else {
+			w = f
+		}
+	}
+	if o.Gzip || strings.HasSuffix(o.File, ".gz") {
+		level := gzip.DefaultCompression
+		if o.GzipLevel != nil {
+			level = *o.GzipLevel
+		}
+		if z, err := GzipWriter(w, level); err != nil {
+			w.Close()
+			return nil, err
+		} else {
+			w = z
+		}
+	}
+	return w, nil
+}
+
+// Open the output stream described by the receiver and answer a WriterBuilder for it.
+func (o *Output) Builder() (WriterBuilder, error) {
+	if w, err := o.Open(); err != nil {
+		return nil, err
+	} else if o.Delimiter != 0 {
+		return WithIoWriterAndDelimiter(w, o.Delimiter), nil
+	} else {
+		return WithIoWriter(w), nil
+	}
+}
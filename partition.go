@@ -0,0 +1,300 @@
+package csv
+
+import (
+	"container/list"
+	encoding "encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// A PartitionWriter splits a stream of CSV records into several files, each with its own header.
+//
+// Records are routed to a partition according to the values of the columns specified by Keys. By
+// default, each partition is written to a Hive-style directory layout below Dir, for example:
+//
+//	Dir/customer=acme/month=2014-12/part-00000.csv
+//
+// Alternatively, Template specifies the name of each file relative to Dir. Within the template, {column}
+// is replaced with the value of the named column, which must be one of the Keys, and {chunk} is replaced
+// with the chunk number, for example: {customer}-{month}-{chunk}.csv
+//
+// If MaxRows or MaxBytes is specified, each partition is further split into numbered chunks of at most
+// the specified number of records or (approximately) bytes. In this case, a Template must contain {chunk}.
+//
+// At most MaxOpen files are held open at once. When the limit is reached, the least recently written
+// file is closed and it is reopened, for appending, if another record is routed to it.
+type PartitionWriter struct {
+	Keys      []string // the columns whose values determine the partition of each record
+	Dir       string   // the directory in which the partition files are created
+	Template  string   // a template for the name of each partition file, relative to Dir. A Hive-style layout is used if empty.
+	MaxOpen   int      // the maximum number of files that are held open at once. Unbounded if zero.
+	MaxRows   int      // the maximum number of records in each chunk. Unbounded if zero.
+	MaxBytes  int64    // the size, in bytes, at which a new chunk is started. Unbounded if zero.
+	Delimiter rune     // the field delimiter of the partition files. ',' is used if zero.
+
+	// Open the named file for writing, truncating it unless appending is true. If nil,
+	// the file is created with os.OpenFile, together with any missing directories.
+	Open func(path string, appending bool) (io.WriteCloser, error)
+}
+
+var placeholderMatcher = regexp.MustCompile(`\{([^}]*)\}`)
+
+// Answer an error if the specification of the receiver is not valid for a stream with the specified header.
+func (p *PartitionWriter) check(header []string) error {
+	keys := utils.NewIndex(p.Keys)
+	if _, x, _ := utils.Intersect(p.Keys, header); len(x) != 0 {
+		return fmt.Errorf("%s does not exist in the data header", Format(x))
+	}
+	if p.Template == "" {
+		return nil
+	}
+	chunked := false
+	for _, m := range placeholderMatcher.FindAllStringSubmatch(p.Template, -1) {
+		if m[1] == "chunk" {
+			chunked = true
+		} else if !keys.Contains(m[1]) {
+			return fmt.Errorf("template refers to %s which is not a partition key", m[1])
+		}
+	}
+	if (p.MaxRows > 0 || p.MaxBytes > 0) && !chunked {
+		return fmt.Errorf("template must contain {chunk} if the partitions are split into chunks")
+	}
+	return nil
+}
+
+// Escape a value so that it can be used as a single element of a file path.
+func escapePathElement(v string) string {
+	switch v {
+	case ".":
+		return "%2E"
+	case "..":
+		return "%2E%2E"
+	default:
+		return url.PathEscape(v)
+	}
+}
+
+// Answer the path of the specified chunk of the partition which contains the specified record.
+func (p *PartitionWriter) path(r Record, chunk int) string {
+	var name string
+	if p.Template == "" {
+		elements := make([]string, 0, len(p.Keys)+1)
+		for _, k := range p.Keys {
+			v := r.Get(k)
+			if v == "" {
+				v = "__HIVE_DEFAULT_PARTITION__"
+			}
+			elements = append(elements, escapePathElement(k)+"="+escapePathElement(v))
+		}
+		elements = append(elements, fmt.Sprintf("part-%05d.csv", chunk))
+		name = filepath.Join(elements...)
+	} else {
+		name = placeholderMatcher.ReplaceAllStringFunc(p.Template, func(m string) string {
+			k := m[1 : len(m)-1]
+			if k == "chunk" {
+				return fmt.Sprintf("%05d", chunk)
+			}
+			return escapePathElement(r.Get(k))
+		})
+	}
+	return filepath.Join(p.Dir, name)
+}
+
+func (p *PartitionWriter) open(path string, appending bool) (io.WriteCloser, error) {
+	if p.Open != nil {
+		return p.Open(path, appending)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return nil, err
+	}
+	flags := os.O_CREATE | os.O_WRONLY
+	if appending {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	return os.OpenFile(path, flags, 0666)
+}
+
+// Answer a WriterBuilder whose Writers route each record written to them to the
+// file of the partition that contains the record.
+func (p *PartitionWriter) Builder() WriterBuilder {
+	return func(header []string) Writer {
+		return &partitionWriter{
+			spec:       p,
+			header:     header,
+			builder:    NewRecordBuilder(header),
+			partitions: map[string]*partition{},
+			created:    map[string]bool{},
+			lru:        list.New(),
+			err:        p.check(header),
+		}
+	}
+}
+
+type partitionWriter struct {
+	spec       *PartitionWriter
+	header     []string
+	builder    RecordBuilder
+	partitions map[string]*partition
+	created    map[string]bool
+	lru        *list.List
+	err        error
+}
+
+type partition struct {
+	chunk   int
+	rows    int
+	bytes   int64
+	path    string
+	file    io.WriteCloser
+	encoder *encoding.Writer
+	element *list.Element
+}
+
+// An io.Writer that counts the bytes written to it.
+type countingWriter struct {
+	w     io.Writer
+	count *int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	*c.count += int64(n)
+	return n, err
+}
+
+func (w *partitionWriter) Header() []string {
+	return w.header
+}
+
+func (w *partitionWriter) Blank() Record {
+	return w.builder(make([]string, len(w.header), len(w.header)))
+}
+
+// Close the file of the specified partition.
+func (w *partitionWriter) release(part *partition) error {
+	part.encoder.Flush()
+	err := part.encoder.Error()
+	if e := part.file.Close(); err == nil {
+		err = e
+	}
+	w.lru.Remove(part.element)
+	part.file = nil
+	part.encoder = nil
+	part.element = nil
+	return err
+}
+
+// Open the file of the current chunk of the specified partition.
+func (w *partitionWriter) acquire(part *partition) error {
+	if w.spec.MaxOpen > 0 && w.lru.Len() >= w.spec.MaxOpen {
+		if err := w.release(w.lru.Back().Value.(*partition)); err != nil {
+			return err
+		}
+	}
+
+	appending := w.created[part.path]
+	file, err := w.spec.open(part.path, appending)
+	if err != nil {
+		return err
+	}
+	w.created[part.path] = true
+
+	part.file = file
+	part.encoder = encoding.NewWriter(&countingWriter{w: file, count: &part.bytes})
+	if w.spec.Delimiter != 0 {
+		part.encoder.Comma = w.spec.Delimiter
+	}
+	part.element = w.lru.PushFront(part)
+	if !appending {
+		return part.encoder.Write(w.header)
+	}
+	return nil
+}
+
+// Write the record into the file of the partition that contains it.
+func (w *partitionWriter) Write(r Record) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	values := make([]string, len(w.spec.Keys))
+	for i, k := range w.spec.Keys {
+		values[i] = r.Get(k)
+	}
+	key := Format(values)
+
+	part, ok := w.partitions[key]
+	if !ok {
+		part = &partition{}
+		part.path = w.spec.path(r, part.chunk)
+		w.partitions[key] = part
+	}
+
+	if part.rows > 0 && ((w.spec.MaxRows > 0 && part.rows >= w.spec.MaxRows) || (w.spec.MaxBytes > 0 && part.bytes >= w.spec.MaxBytes)) {
+		if part.file != nil {
+			if err := w.release(part); err != nil {
+				return err
+			}
+		}
+		part.chunk++
+		part.rows = 0
+		part.bytes = 0
+		part.path = w.spec.path(r, part.chunk)
+	}
+
+	if part.file == nil {
+		if w.err = w.acquire(part); w.err != nil {
+			return w.err
+		}
+	} else {
+		w.lru.MoveToFront(part.element)
+	}
+
+	var fields []string
+	h := r.Header()
+	if len(h) > 0 && len(w.header) == len(h) && &h[0] == &w.header[0] {
+		fields = r.AsSlice()
+	} else {
+		fields = make([]string, len(w.header))
+		for i, k := range w.header {
+			fields[i] = r.Get(k)
+		}
+	}
+
+	if w.err = part.encoder.Write(fields); w.err != nil {
+		return w.err
+	}
+	part.rows++
+	if w.spec.MaxBytes > 0 {
+		// flush so that the byte count reflects the size of the chunk
+		part.encoder.Flush()
+		w.err = part.encoder.Error()
+	}
+	return w.err
+}
+
+func (w *partitionWriter) Error() error {
+	return w.err
+}
+
+// Close all the open partition files.
+func (w *partitionWriter) Close(err error) error {
+	var result error
+	for w.lru.Len() > 0 {
+		if e := w.release(w.lru.Front().Value.(*partition)); result == nil {
+			result = e
+		}
+	}
+	if w.err == nil {
+		w.err = err
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package csv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPartitionWriterRowsTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "partition")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &PartitionWriter{Dir: dir, Template: "chunk-{chunk}.csv", MaxRows: 2}
+	errCh := make(chan error, 1)
+	(&CatProcess{}).Run(stringReader("k,v\na,1\nb,2\nc,3\n"), p.Builder(), errCh)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"chunk-00000.csv": "k,v\na,1\nb,2\n",
+		"chunk-00001.csv": "k,v\nc,3\n",
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %d files, found %d", len(expected), len(files))
+	}
+	for name, data := range expected {
+		if b, err := ioutil.ReadFile(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		} else if string(b) != data {
+			t.Fatalf("%s: expected:\n%s\ngot:\n%s", name, data, string(b))
+		}
+	}
+}
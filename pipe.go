@@ -1,5 +1,14 @@
 package csv
 
+import (
+	"errors"
+	"sync"
+)
+
+// ErrReaderClosed is answered by the Write method of the writer of a Pipe whose Reader has been
+// closed. A process that receives this error should stop writing and close its own reader.
+var ErrReaderClosed = errors.New("the reader has been closed")
+
 // Implements a unidirectional channel that can connect a reader process to a writer process.
 type Pipe interface {
 	Builder() WriterBuilder // Builds a Writer for the write end of the pipe
@@ -10,6 +19,8 @@ type pipe struct {
 	header []string
 	ch     chan Record
 	init   chan interface{}
+	quit   chan interface{}
+	once   sync.Once
 	err    error
 }
 
@@ -25,6 +36,7 @@ func NewPipe() Pipe {
 		ch:   make(chan Record),
 		err:  nil,
 		init: make(chan interface{}),
+		quit: make(chan interface{}),
 	}
 }
 
@@ -38,6 +50,9 @@ func (p *pipe) C() <-chan Record {
 }
 
 func (p *pipe) Close() {
+	p.once.Do(func() {
+		close(p.quit)
+	})
 }
 
 func (p *pipe) Header() []string {
@@ -77,8 +92,12 @@ func (p *pipeWriter) Header() []string {
 }
 
 func (p *pipeWriter) Write(r Record) error {
-	p.pipe.ch <- r
-	return nil
+	select {
+	case p.pipe.ch <- r:
+		return nil
+	case <-p.pipe.quit:
+		return ErrReaderClosed
+	}
 }
 
 // A pipeline of processes.
@@ -115,7 +134,9 @@ func (p *pipeline) Run(r Reader, b WriterBuilder, errCh chan<- error) {
 		for running > 0 {
 			e := <-errors
 			running--
-			if err == nil {
+			// a stage that stops early closes its reader, which causes the stages upstream
+			// of it to stop with ErrReaderClosed
+			if err == nil && e != ErrReaderClosed {
 				err = e
 			}
 		}
@@ -0,0 +1,128 @@
+package csv
+
+import (
+	"fmt"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// Given a header-prefixed input stream of CSV records, a set of identifying columns (IdKeys), a column
+// that contains column names (VariableColumn) and a column that contains values (ValueColumn), PivotProcess
+// performs the inverse of MeltProcess. It generates one output record for each distinct combination of
+// identifying values, in order of first appearance. Each output record contains the identifying columns
+// and one column for each distinct value of VariableColumn, which contains the corresponding values of
+// ValueColumn. Other input columns are discarded.
+//
+// When more than one input record contributes a value to the same output field, the values are combined
+// by the named Aggregation (see NewAggregatorFactory). The default aggregation, single, reports an error
+// if the values differ.
+//
+// The output columns are discovered from the input stream, in order of first appearance, unless Columns
+// is specified, in which case exactly those columns are generated and records whose variable does not
+// name one of them are discarded. Since the output header is not known until the whole input stream has
+// been read, the input stream is buffered in memory.
+type PivotProcess struct {
+	IdKeys         []string // the columns that identify each output record
+	VariableColumn string   // the column that contains the names of the output columns
+	ValueColumn    string   // the column that contains the values of the output columns
+	Columns        []string // the output columns. Discovered from the input stream if empty.
+	Aggregation    string   // the aggregation used to combine colliding values. single is used if empty.
+}
+
+type pivotRow struct {
+	ids    []string
+	values map[string]Aggregator
+}
+
+func (p *PivotProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+
+		required := append(append([]string{}, p.IdKeys...), p.VariableColumn, p.ValueColumn)
+		if _, x, _ := utils.Intersect(required, dataHeader); len(x) != 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+
+		aggregation := p.Aggregation
+		if aggregation == "" {
+			aggregation = "single"
+		}
+		factory, err := NewAggregatorFactory(aggregation)
+		if err != nil {
+			return err
+		}
+
+		columns := p.Columns
+		explicit := utils.NewIndex(p.Columns)
+		discovered := map[string]bool{}
+
+		rows := []*pivotRow{}
+		index := map[string]*pivotRow{}
+
+		for data := range reader.C() {
+			variable := data.Get(p.VariableColumn)
+			if len(p.Columns) > 0 {
+				if !explicit.Contains(variable) {
+					continue
+				}
+			} else if !discovered[variable] {
+				discovered[variable] = true
+				columns = append(columns, variable)
+			}
+
+			ids := make([]string, len(p.IdKeys))
+			for i, k := range p.IdKeys {
+				ids[i] = data.Get(k)
+			}
+			key := Format(ids)
+			row, ok := index[key]
+			if !ok {
+				row = &pivotRow{ids: ids, values: map[string]Aggregator{}}
+				index[key] = row
+				rows = append(rows, row)
+			}
+
+			agg, ok := row.values[variable]
+			if !ok {
+				agg = factory()
+				row.values[variable] = agg
+			}
+			if err = agg.Add(data.Get(p.ValueColumn)); err != nil {
+				return fmt.Errorf("%s: %s: %v", key, variable, err)
+			}
+		}
+
+		if err = reader.Error(); err != nil {
+			return err
+		}
+
+		outputHeader := make([]string, len(p.IdKeys), len(p.IdKeys)+len(columns))
+		copy(outputHeader, p.IdKeys)
+		outputHeader = append(outputHeader, columns...)
+		if len(utils.NewIndex(outputHeader)) != len(outputHeader) {
+			return fmt.Errorf("output header contains duplicate columns: %s", Format(outputHeader))
+		}
+
+		writer := builder(outputHeader)
+		defer writer.Close(err)
+
+		for _, row := range rows {
+			o := writer.Blank()
+			for i, k := range p.IdKeys {
+				o.Put(k, row.ids[i])
+			}
+			for _, c := range columns {
+				if agg, ok := row.values[c]; ok {
+					o.Put(c, agg.Value())
+				}
+			}
+			if err = writer.Write(o); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}()
+}
@@ -0,0 +1,289 @@
+package csv
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// The quantiles estimated by a ProfileProcess by default.
+var DefaultProfileQuantiles = []float64{0.25, 0.5, 0.75}
+
+const (
+	// the number of frequent values reported by a ProfileProcess by default
+	DefaultProfileTopN = 5
+	// the accuracy parameter of the quantile sketch
+	profileSketchSize = 200
+	// the precision of the HyperLogLog sketch; the standard error is about 0.8%
+	profilePrecision = 14
+	// the number of counters used to track the most frequent values in approximate mode, per value reported
+	profileCountersPerValue = 10
+)
+
+// Given a header-prefixed input stream of CSV records, ProfileProcess generates a report that contains
+// one record for each of the specified columns (Columns), or for every column if none are specified.
+// Each record of the report contains:
+//
+//	column - the name of the column
+//	type - the inferred type of the non-empty values: integer, float, boolean, timestamp, string or empty
+//	count - the number of values
+//	empty - the number of empty values
+//	distinct - the number of distinct non-empty values
+//	min, max - the least and greatest non-empty values, compared numerically for numeric columns
+//	mean, stddev - the mean and sample standard deviation of numeric columns
+//	p<n> - the approximate n-th percentile of numeric columns, for each of the Quantiles
+//	top - the most frequent non-empty values, with their counts, in the form value:count
+//
+// Timestamps are values in RFC 3339 format or of the form 2006-01-02 or 2006-01-02 15:04:05.
+//
+// The quantiles are estimated with a streaming sketch and are exact for small inputs. If Approximate is
+// true, the distinct counts are estimated with a HyperLogLog sketch and the most frequent values are
+// tracked, with approximate counts, by a bounded number of counters, so that the memory used does not grow with the number of
+// distinct values. Otherwise, every distinct value of every column is held in memory.
+type ProfileProcess struct {
+	Columns     []string  // the columns to profile. Every column is profiled if empty.
+	Approximate bool      // estimate distinct counts and frequent values in bounded memory
+	Quantiles   []float64 // the quantiles to estimate. DefaultProfileQuantiles if nil.
+	TopN        int       // the number of frequent values to report. DefaultProfileTopN if zero.
+}
+
+var profileTimestampLayouts = []string{time.RFC3339Nano, "2006-01-02", "2006-01-02 15:04:05"}
+
+// The accumulated statistics of one column.
+type columnProfile struct {
+	count, empty int
+
+	integer, float, boolean, timestamp bool
+
+	// the least and greatest values compared lexically and, while the column is numeric, numerically
+	min, max               string
+	numericMin, numericMax string
+
+	// running mean and sum of squared differences, per Welford
+	n        int
+	mean, m2 float64
+	sketch   *kllSketch
+
+	exact    map[string]int
+	distinct *hyperLogLog
+	frequent *spaceSaving
+}
+
+func (p *ProfileProcess) newColumnProfile(topN int) *columnProfile {
+	c := &columnProfile{integer: true, float: true, boolean: true, timestamp: true, sketch: newKllSketch(profileSketchSize)}
+	if p.Approximate {
+		c.distinct = newHyperLogLog(profilePrecision)
+		c.frequent = newSpaceSaving(topN * profileCountersPerValue)
+	} else {
+		c.exact = map[string]int{}
+	}
+	return c
+}
+
+func (c *columnProfile) add(v string) {
+	c.count++
+	if v == "" {
+		c.empty++
+		return
+	}
+
+	if c.exact != nil {
+		c.exact[v]++
+	} else {
+		c.distinct.add(v)
+		c.frequent.add(v)
+	}
+
+	t := strings.TrimSpace(v)
+	if c.integer {
+		if _, err := strconv.ParseInt(t, 10, 64); err != nil {
+			c.integer = false
+		}
+	}
+	if c.float {
+		if f, err := strconv.ParseFloat(t, 64); err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			c.float = false
+		} else {
+			c.n++
+			delta := f - c.mean
+			c.mean += delta / float64(c.n)
+			c.m2 += delta * (f - c.mean)
+			c.sketch.add(f)
+		}
+	}
+	if c.boolean {
+		switch strings.ToLower(t) {
+		case "true", "false":
+		default:
+			c.boolean = false
+		}
+	}
+	if c.timestamp {
+		parsed := false
+		for _, layout := range profileTimestampLayouts {
+			if _, err := time.Parse(layout, t); err == nil {
+				parsed = true
+				break
+			}
+		}
+		c.timestamp = parsed
+	}
+
+	if c.min == "" {
+		c.min, c.max = v, v
+		c.numericMin, c.numericMax = v, v
+	} else {
+		if v < c.min {
+			c.min = v
+		}
+		if v > c.max {
+			c.max = v
+		}
+		if c.float {
+			if LessNumericStrings(v, c.numericMin) {
+				c.numericMin = v
+			}
+			if LessNumericStrings(c.numericMax, v) {
+				c.numericMax = v
+			}
+		}
+	}
+}
+
+// Answer the least and greatest values of the column, compared numerically if the column is numeric.
+func (c *columnProfile) bounds() (string, string) {
+	switch c.kind() {
+	case "integer", "float":
+		return c.numericMin, c.numericMax
+	default:
+		return c.min, c.max
+	}
+}
+
+// Answer the inferred type of the column.
+func (c *columnProfile) kind() string {
+	switch {
+	case c.count == c.empty:
+		return "empty"
+	case c.integer:
+		return "integer"
+	case c.float:
+		return "float"
+	case c.boolean:
+		return "boolean"
+	case c.timestamp:
+		return "timestamp"
+	default:
+		return "string"
+	}
+}
+
+// Answer the name of the report column that contains the specified quantile.
+func quantileColumn(q float64) string {
+	return "p" + formatFloat(q*100)
+}
+
+func (p *ProfileProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+		columns := p.Columns
+		if len(columns) == 0 {
+			columns = dataHeader
+		} else if _, x, _ := utils.Intersect(columns, dataHeader); len(x) != 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+
+		quantiles := p.Quantiles
+		if quantiles == nil {
+			quantiles = DefaultProfileQuantiles
+		}
+		for _, q := range quantiles {
+			if q < 0 || q > 1 {
+				return fmt.Errorf("invalid quantile: %v", q)
+			}
+		}
+		topN := p.TopN
+		if topN == 0 {
+			topN = DefaultProfileTopN
+		}
+
+		profiles := make([]*columnProfile, len(columns))
+		for i := range profiles {
+			profiles[i] = p.newColumnProfile(topN)
+		}
+
+		for data := range reader.C() {
+			for i, k := range columns {
+				profiles[i].add(data.Get(k))
+			}
+		}
+		if err = reader.Error(); err != nil {
+			return err
+		}
+
+		header := []string{"column", "type", "count", "empty", "distinct", "min", "max", "mean", "stddev"}
+		for _, q := range quantiles {
+			header = append(header, quantileColumn(q))
+		}
+		header = append(header, "top")
+		if len(utils.NewIndex(header)) != len(header) {
+			return fmt.Errorf("report header contains duplicate columns: %s", Format(header))
+		}
+
+		writer := builder(header)
+		defer writer.Close(err)
+
+		for i, k := range columns {
+			c := profiles[i]
+			o := writer.Blank()
+			o.Put("column", k)
+			o.Put("type", c.kind())
+			o.Put("count", strconv.Itoa(c.count))
+			o.Put("empty", strconv.Itoa(c.empty))
+			min, max := c.bounds()
+			o.Put("min", min)
+			o.Put("max", max)
+
+			var top []frequentValue
+			if c.exact != nil {
+				o.Put("distinct", strconv.Itoa(len(c.exact)))
+				for v, n := range c.exact {
+					top = append(top, frequentValue{value: v, count: n})
+				}
+				sortFrequentValues(top)
+			} else {
+				o.Put("distinct", strconv.FormatUint(c.distinct.count(), 10))
+				top = c.frequent.top()
+			}
+			if len(top) > topN {
+				top = top[:topN]
+			}
+			values := make([]string, len(top))
+			for x, v := range top {
+				values[x] = v.value + ":" + strconv.Itoa(v.count)
+			}
+			o.Put("top", Format(values))
+
+			if c.float && c.n > 0 {
+				o.Put("mean", formatFloat(c.mean))
+				if c.n > 1 {
+					o.Put("stddev", formatFloat(math.Sqrt(c.m2/float64(c.n-1))))
+				}
+				for _, q := range quantiles {
+					o.Put(quantileColumn(q), formatFloat(c.sketch.quantile(q)))
+				}
+			}
+
+			if err = writer.Write(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+}
@@ -3,6 +3,7 @@ package csv
 import (
 	"encoding/csv"
 	"io"
+	"sync"
 )
 
 // Reader provides a reader of CSV streams whose first record is a header describing each field.
@@ -14,13 +15,16 @@ type Reader interface {
 	C() <-chan Record
 	// Answers the error that caused the stream to close, if any.
 	Error() error
-	// Close the reader and release any resources associated with it.
+	// Close the reader and release any resources associated with it. A reader may be
+	// closed before the stream is exhausted, in which case the producer of the stream stops
+	// producing records. It is safe to close a reader more than once.
 	Close()
 }
 
 type reader struct {
 	init   chan interface{}
 	quit   chan interface{}
+	once   sync.Once
 	header []string
 	err    error
 	io     <-chan Record
@@ -88,10 +92,9 @@ func WithCsvReader(r *csv.Reader, c io.Closer) Reader {
 			} else {
 				select {
 				case <-result.quit:
-					break
-				default:
+					return
+				case ch <- builder(a):
 				}
-				ch <- builder(a)
 			}
 		}
 	}()
@@ -113,7 +116,9 @@ func (reader *reader) C() <-chan Record {
 }
 
 func (reader *reader) Close() {
-	close(reader.quit)
+	reader.once.Do(func() {
+		close(reader.quit)
+	})
 }
 
 // Given a reader and a process, answer a new reader which is the result of
@@ -0,0 +1,232 @@
+package csv
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// A ResampleFill determines how a ResampleProcess fills the values of intervals that contain no data.
+type ResampleFill int
+
+const (
+	FillNull     ResampleFill = iota // leave the value empty
+	FillPrevious                     // use the value of the preceding interval
+	FillLinear                       // interpolate linearly between the values of the surrounding intervals
+)
+
+// Given a header-prefixed input stream of CSV records that contain a timestamp column (Timestamp),
+// ResampleProcess generates an output stream that contains one record for each fixed length interval
+// (Interval) of each group of records with the same tag values (Tags).
+//
+// The timestamp is parsed according to Format and Location, per TimestampFormat. Intervals are aligned
+// to multiples of Interval since the Unix epoch. Each output record contains the tag columns, the start of
+// the interval, formatted like the input timestamps, and the aggregate (per NewAggregatorFactory) of the
+// values of each value column (Values) of the records within the interval. The default aggregation is mean.
+//
+// Every interval between the first and last interval of each group is generated. The values of an interval
+// that contains no data are filled according to Fill.
+//
+// The input stream need not be sorted. The output stream contains the groups in order of first appearance
+// and the intervals of each group in chronological order. The aggregates of the intervals that contain data
+// are held in memory until the input stream is exhausted; the empty intervals between them are generated as
+// they are written, so memory does not grow with the length of the gaps.
+type ResampleProcess struct {
+	Timestamp   string        // the name of the timestamp column
+	Format      string        // the format of the timestamp column: s, ms, ns or a go time layout
+	Location    string        // the location in which the timestamp is interpreted (per go time.LoadLocation())
+	Interval    time.Duration // the length of each interval
+	Tags        []string      // the columns that identify each group of records
+	Values      []string      // the columns that are aggregated
+	Aggregation string        // the aggregation applied to the values of each interval. mean is used if empty.
+	Fill        ResampleFill  // how the values of empty intervals are filled
+}
+
+type resampleGroup struct {
+	tags    []string
+	buckets map[int64][]Aggregator
+}
+
+// Answer the number of the interval that contains the specified time.
+func (p *ResampleProcess) bucket(t time.Time) int64 {
+	ns, d := t.UnixNano(), int64(p.Interval)
+	b := ns / d
+	if ns%d < 0 {
+		b--
+	}
+	return b
+}
+
+// Answer the filled value of an empty interval, x, of a column whose last non-empty value, v0, is that of
+// interval x0 and whose next non-empty value, v1, is that of interval x1. ok0 and ok1 are false if there is
+// no such value.
+func (p *ResampleProcess) fill(x int64, x0 int64, v0 string, ok0 bool, x1 int64, v1 string, ok1 bool) string {
+	switch p.Fill {
+	case FillPrevious:
+		return v0
+	case FillLinear:
+		if ok0 && ok1 {
+			f0, e0 := parseFloat(v0)
+			f1, e1 := parseFloat(v1)
+			if e0 == nil && e1 == nil {
+				return formatFloat(f0 + (f1-f0)*float64(x-x0)/float64(x1-x0))
+			}
+		}
+	}
+	return ""
+}
+
+func (p *ResampleProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+
+		required := append(append([]string{p.Timestamp}, p.Tags...), p.Values...)
+		if _, x, _ := utils.Intersect(required, dataHeader); len(x) != 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+		if p.Interval <= 0 {
+			return fmt.Errorf("the interval must be positive")
+		}
+
+		format, err := NewTimestampFormat(p.Format, p.Location)
+		if err != nil {
+			return err
+		}
+
+		aggregation := p.Aggregation
+		if aggregation == "" {
+			aggregation = "mean"
+		}
+		factory, err := NewAggregatorFactory(aggregation)
+		if err != nil {
+			return err
+		}
+
+		groups := []*resampleGroup{}
+		index := map[string]*resampleGroup{}
+
+		for data := range reader.C() {
+			ts, err := format.Parse(data.Get(p.Timestamp))
+			if err != nil {
+				return err
+			}
+			b := p.bucket(ts)
+
+			tags := make([]string, len(p.Tags))
+			for i, k := range p.Tags {
+				tags[i] = data.Get(k)
+			}
+			key := Format(tags)
+			g, ok := index[key]
+			if !ok {
+				g = &resampleGroup{tags: tags, buckets: map[int64][]Aggregator{}}
+				index[key] = g
+				groups = append(groups, g)
+			}
+			aggs, ok := g.buckets[b]
+			if !ok {
+				aggs = make([]Aggregator, len(p.Values))
+				for i := range aggs {
+					aggs[i] = factory()
+				}
+				g.buckets[b] = aggs
+			}
+			for i, k := range p.Values {
+				if err := aggs[i].Add(data.Get(k)); err != nil {
+					return fmt.Errorf("%s: %v", k, err)
+				}
+			}
+		}
+
+		if err = reader.Error(); err != nil {
+			return err
+		}
+
+		outputHeader := make([]string, len(p.Tags), len(p.Tags)+1+len(p.Values))
+		copy(outputHeader, p.Tags)
+		outputHeader = append(outputHeader, p.Timestamp)
+		outputHeader = append(outputHeader, p.Values...)
+		if len(utils.NewIndex(outputHeader)) != len(outputHeader) {
+			return fmt.Errorf("output header contains duplicate columns: %s", Format(outputHeader))
+		}
+
+		writer := builder(outputHeader)
+		defer writer.Close(err)
+
+		for _, g := range groups {
+			// the intervals that contain data, in chronological order, and their values
+			present := make([]int64, 0, len(g.buckets))
+			for b := range g.buckets {
+				present = append(present, b)
+			}
+			sort.Slice(present, func(i, j int) bool { return present[i] < present[j] })
+			values := make([][]string, len(present))
+			for j, b := range present {
+				values[j] = make([]string, len(p.Values))
+				for i, a := range g.buckets[b] {
+					values[j][i] = a.Value()
+				}
+			}
+
+			// next[j][i] is the index of the first interval, at or after present[j], with a value of column i or -1
+			next := make([][]int, len(present)+1)
+			next[len(present)] = make([]int, len(p.Values))
+			for i := range p.Values {
+				next[len(present)][i] = -1
+			}
+			for j := len(present) - 1; j >= 0; j-- {
+				next[j] = make([]int, len(p.Values))
+				for i := range p.Values {
+					if values[j][i] != "" {
+						next[j][i] = j
+					} else {
+						next[j][i] = next[j+1][i]
+					}
+				}
+			}
+
+			// the last non-empty value of each column and its interval
+			last := make([]string, len(p.Values))
+			lastInterval := make([]int64, len(p.Values))
+			seen := make([]bool, len(p.Values))
+
+			// write the intervals one at a time, so that the empty intervals are never held in memory
+			for j, b := range present {
+				x := b
+				if j > 0 {
+					x = present[j-1] + 1
+				}
+				for ; x <= b; x++ {
+					o := writer.Blank()
+					for i, k := range p.Tags {
+						o.Put(k, g.tags[i])
+					}
+					o.Put(p.Timestamp, format.Format(time.Unix(0, x*int64(p.Interval))))
+					for i, k := range p.Values {
+						v := ""
+						if x == b {
+							v = values[j][i]
+						}
+						if v != "" {
+							last[i], lastInterval[i], seen[i] = v, x, true
+						} else if n := next[j][i]; n >= 0 {
+							v = p.fill(x, lastInterval[i], last[i], seen[i], present[n], values[n][i], true)
+						} else {
+							v = p.fill(x, lastInterval[i], last[i], seen[i], 0, "", false)
+						}
+						o.Put(k, v)
+					}
+					if err = writer.Write(o); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	}()
+}
@@ -0,0 +1,191 @@
+package csv
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// A HeadProcess copies the first Count records of the input stream to the output stream. The
+// reader is closed as soon as Count records have been copied, so that the producers of the input
+// stream can stop early.
+type HeadProcess struct {
+	Count int
+}
+
+func (p *HeadProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		writer := builder(reader.Header())
+		defer writer.Close(err)
+
+		if p.Count <= 0 {
+			return nil
+		}
+
+		n := 0
+		for data := range reader.C() {
+			if err := writer.Write(data); err != nil {
+				return err
+			}
+			n++
+			if n >= p.Count {
+				return nil
+			}
+		}
+		return reader.Error()
+	}()
+}
+
+// A TailProcess copies the last Count records of the input stream to the output stream.
+type TailProcess struct {
+	Count int
+}
+
+func (p *TailProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		writer := builder(reader.Header())
+		defer writer.Close(err)
+
+		if p.Count <= 0 {
+			return nil
+		}
+
+		// a ring buffer containing the last Count records
+		ring := make([]Record, p.Count)
+		n := 0
+		for data := range reader.C() {
+			ring[n%p.Count] = data
+			n++
+		}
+		if err := reader.Error(); err != nil {
+			return err
+		}
+
+		start := 0
+		if n > p.Count {
+			start = n - p.Count
+		}
+		for i := start; i < n; i++ {
+			if err := writer.Write(ring[i%p.Count]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+}
+
+// A ReservoirSampleProcess copies a uniform random sample of Size records of the input stream to the
+// output stream, preserving the order of the input stream.
+//
+// If StrataKeys are specified, the input stream is stratified by the values of the specified
+// columns and a sample of at most Size records is drawn from each stratum.
+//
+// The sample is reproducible: the same input stream and Seed always yield the same sample.
+type ReservoirSampleProcess struct {
+	Size       int      // the number of records to sample (from each stratum)
+	Seed       int64    // the seed of the random number generator
+	StrataKeys []string // the columns that determine the stratum of each record
+}
+
+type sampledRecord struct {
+	record Record
+	index  int
+}
+
+func (p *ReservoirSampleProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+		if _, x, _ := utils.Intersect(p.StrataKeys, dataHeader); len(x) > 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+
+		writer := builder(dataHeader)
+		defer writer.Close(err)
+
+		if p.Size <= 0 {
+			return nil
+		}
+
+		random := rand.New(rand.NewSource(p.Seed))
+		type reservoir struct {
+			seen    int
+			samples []sampledRecord
+		}
+		reservoirs := map[string]*reservoir{}
+		key := make([]string, len(p.StrataKeys))
+
+		n := 0
+		for data := range reader.C() {
+			for i, k := range p.StrataKeys {
+				key[i] = data.Get(k)
+			}
+			formattedKey := Format(key)
+			r, ok := reservoirs[formattedKey]
+			if !ok {
+				r = &reservoir{samples: make([]sampledRecord, 0, p.Size)}
+				reservoirs[formattedKey] = r
+			}
+
+			r.seen++
+			if len(r.samples) < p.Size {
+				r.samples = append(r.samples, sampledRecord{record: data, index: n})
+			} else if j := random.Intn(r.seen); j < p.Size {
+				r.samples[j] = sampledRecord{record: data, index: n}
+			}
+			n++
+		}
+		if err := reader.Error(); err != nil {
+			return err
+		}
+
+		all := []sampledRecord{}
+		for _, r := range reservoirs {
+			all = append(all, r.samples...)
+		}
+		sort.Slice(all, func(i, j int) bool {
+			return all[i].index < all[j].index
+		})
+
+		for _, s := range all {
+			if err := writer.Write(s.record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+}
+
+// A BernoulliSampleProcess copies each record of the input stream to the output stream with
+// the specified Probability. The sample is reproducible: the same input stream and Seed always
+// yield the same sample.
+type BernoulliSampleProcess struct {
+	Probability float64 // the probability with which each record is sampled
+	Seed        int64   // the seed of the random number generator
+}
+
+func (p *BernoulliSampleProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		writer := builder(reader.Header())
+		defer writer.Close(err)
+
+		random := rand.New(rand.NewSource(p.Seed))
+		for data := range reader.C() {
+			if random.Float64() < p.Probability {
+				if err := writer.Write(data); err != nil {
+					return err
+				}
+			}
+		}
+		return reader.Error()
+	}()
+}
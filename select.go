@@ -1,43 +1,162 @@
 package csv
 
 import (
+	"fmt"
+	"path"
+	"regexp"
+
 	"github.com/wildducktheories/go-csv/utils"
 )
 
+// A ColumnMatch determines how the keys of a SelectProcess are matched against the columns of the input stream.
+type ColumnMatch int
+
+const (
+	MatchExact  ColumnMatch = iota // each key is the name of a column
+	MatchGlob                      // each key is a glob pattern (per path.Match) that matches zero or more columns
+	MatchRegexp                    // each key is a regular expression that must match the whole name of zero or more columns
+)
+
 // Given a header-prefixed input stream of CSV records select the fields that match the specified key (Key).
 // If PermuteOnly is is specified, all the fields of the input stream are preserved, but the output stream
 // is permuted so that the key fields occupy the left-most fields of the output stream. The remaining fields
 // are preserved in their original order.
+//
+// If Exclude is specified, the output stream contains every field of the input stream except those that
+// match the keys. Match determines whether keys are matched against the column names exactly, as glob
+// patterns or as regular expressions. Rename maps the names of selected input columns to the names used in
+// the output stream.
+//
+// By default, an exact key that matches no column produces an empty column in the output stream. If Strict
+// is specified, it is an error for any key, or any column named by Rename, to match no column.
 type SelectProcess struct {
 	Keys        []string
 	PermuteOnly bool
+	Exclude     bool              // select every column except those that match the keys
+	Match       ColumnMatch       // how the keys are matched against the columns of the input stream
+	Rename      map[string]string // maps the names of input columns to the names of output columns
+	Strict      bool              // fail if a key matches no column
+}
+
+// Answer the columns of the specified header that are matched by the keys.
+func (p *SelectProcess) match(header []string) ([]string, error) {
+	index := utils.NewIndex(header)
+	result := []string{}
+	selected := map[string]bool{}
+	add := func(k string) {
+		if !selected[k] {
+			selected[k] = true
+			result = append(result, k)
+		}
+	}
+
+	for _, k := range p.Keys {
+		var matcher func(string) bool
+		switch p.Match {
+		case MatchGlob:
+			if _, err := path.Match(k, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern: %s: %v", k, err)
+			}
+			matcher = func(c string) bool {
+				ok, _ := path.Match(k, c)
+				return ok
+			}
+		case MatchRegexp:
+			if re, err := regexp.Compile("^(?:" + k + ")$"); err != nil {
+				return nil, fmt.Errorf("invalid regular expression: %s: %v", k, err)
+			} else {
+				matcher = re.MatchString
+			}
+		default:
+			if index.Contains(k) || !p.Strict {
+				add(k)
+				continue
+			}
+		}
+
+		matched := false
+		if matcher != nil {
+			for _, c := range header {
+				if matcher(c) {
+					matched = true
+					add(c)
+				}
+			}
+		}
+		if !matched && p.Strict {
+			return nil, fmt.Errorf("%s does not match any column of the data header", k)
+		}
+	}
+
+	if p.Strict {
+		for k := range p.Rename {
+			if !index.Contains(k) {
+				return nil, fmt.Errorf("%s does not exist in the data header", k)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Answer the names of the input columns that are copied into the output stream and the
+// corresponding output header.
+func (p *SelectProcess) headers(dataHeader []string) ([]string, []string, error) {
+	if p.Exclude && p.PermuteOnly {
+		return nil, nil, fmt.Errorf("exclusion cannot be combined with permutation")
+	}
+
+	keys, err := p.match(dataHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, _, b := utils.Intersect(keys, dataHeader)
+	if p.Exclude {
+		keys = b
+	} else if len(b) > 0 && p.PermuteOnly {
+		extend := make([]string, len(keys)+len(b))
+		copy(extend, keys)
+		copy(extend[len(keys):], b)
+		keys = extend
+	}
+
+	outputHeader := make([]string, len(keys))
+	for i, k := range keys {
+		if r, ok := p.Rename[k]; ok {
+			outputHeader[i] = r
+		} else {
+			outputHeader[i] = k
+		}
+	}
+	if len(utils.NewIndex(outputHeader)) != len(outputHeader) {
+		return nil, nil, fmt.Errorf("output header contains duplicate columns: %s", Format(outputHeader))
+	}
+
+	return keys, outputHeader, nil
 }
 
 func (p *SelectProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
 	errCh <- func() (err error) {
 		defer reader.Close()
 
-		keys := p.Keys
-		permuteOnly := p.PermuteOnly
-
 		// get the data header
 		dataHeader := reader.Header()
 
-		_, _, b := utils.Intersect(keys, dataHeader)
-		if len(b) > 0 && permuteOnly {
-			extend := make([]string, len(keys)+len(b))
-			copy(extend, keys)
-			copy(extend[len(keys):], b)
-			keys = extend
+		keys, outputHeader, err := p.headers(dataHeader)
+		if err != nil {
+			return err
 		}
 
 		// create a new output stream
-		writer := builder(keys)
+		writer := builder(outputHeader)
 		defer writer.Close(err)
 
 		for data := range reader.C() {
 			outputData := writer.Blank()
-			outputData.PutAll(data)
+			for i, k := range keys {
+				outputData.Put(outputHeader[i], data.Get(k))
+			}
 			if err = writer.Write(outputData); err != nil {
 				return err
 			}
@@ -0,0 +1,296 @@
+package csv
+
+import (
+	"container/heap"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"math/rand"
+	"sort"
+)
+
+// A bloomFilter is a probabilistic set of strings. It never answers false for a string that
+// has been added to it, but it may answer true for a string that has not.
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    int
+}
+
+// Answer a bloom filter sized so that, once n strings have been added to it, the probability of a
+// false positive is approximately p. It is an error unless n is positive and p lies strictly between 0 and 1.
+func newBloomFilter(n int, p float64) (*bloomFilter, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("the capacity of a bloom filter must be positive: %d", n)
+	}
+	if !(p > 0 && p < 1) {
+		return nil, fmt.Errorf("the false positive rate of a bloom filter must be between 0 and 1: %v", p)
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}, nil
+}
+
+// Add the string to the filter, answering true if the filter (probably) already contained it.
+func (b *bloomFilter) add(s string) bool {
+	// derive k hashes from two independent hashes (Kirsch and Mitzenmacher)
+	h1 := fnv.New64a()
+	h1.Write([]byte(s))
+	h2 := fnv.New64()
+	h2.Write([]byte(s))
+	x, y := h1.Sum64(), h2.Sum64()|1
+
+	present := true
+	for i := 0; i < b.k; i++ {
+		bit := (x + uint64(i)*y) % b.m
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if b.bits[word]&mask == 0 {
+			present = false
+			b.bits[word] |= mask
+		}
+	}
+	return present
+}
+
+// Mix the bits of a 64-bit hash (the finalizer of splitmix64) so that every bit of the result
+// depends on every bit of the input.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Answer a well-distributed 64-bit hash of the specified string.
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return mix64(h.Sum64())
+}
+
+// A hyperLogLog estimates the number of distinct strings added to it, using 2^p one byte registers.
+// The standard error of the estimate is approximately 1.04/sqrt(2^p).
+type hyperLogLog struct {
+	p         uint
+	registers []uint8
+}
+
+// Answer a HyperLogLog sketch with 2^p registers.
+func newHyperLogLog(p uint) *hyperLogLog {
+	return &hyperLogLog{p: p, registers: make([]uint8, 1<<p)}
+}
+
+func (h *hyperLogLog) add(s string) {
+	x := hash64(s)
+	i := x >> (64 - h.p)
+	w := x<<h.p | 1<<(h.p-1)
+	rank := uint8(bits.LeadingZeros64(w) + 1)
+	if rank > h.registers[i] {
+		h.registers[i] = rank
+	}
+}
+
+// Answer the estimated number of distinct strings.
+func (h *hyperLogLog) count() uint64 {
+	m := float64(len(h.registers))
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// linear counting is more accurate for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+// A kllSketch summarizes a stream of numbers in bounded space so that approximate quantiles can be
+// answered. It is a variant of the sketch described by Karnin, Lang and Liberty: a hierarchy of
+// compactors, each of which, when full, sorts its items and promotes every other item to the next
+// level, where it carries twice the weight. The answers are exact until the first compaction.
+type kllSketch struct {
+	k          int
+	compactors [][]float64
+	size       int
+	maxSize    int
+	rand       *rand.Rand
+}
+
+// Answer a sketch whose accuracy is determined by k; the rank error is roughly 1.7/k.
+func newKllSketch(k int) *kllSketch {
+	s := &kllSketch{k: k, rand: rand.New(rand.NewSource(1))}
+	s.grow()
+	return s
+}
+
+// Answer the capacity of the compactor at the specified level.
+func (s *kllSketch) capacity(level int) int {
+	depth := len(s.compactors) - level - 1
+	return int(math.Ceil(float64(s.k)*math.Pow(2.0/3.0, float64(depth)))) + 1
+}
+
+func (s *kllSketch) grow() {
+	s.compactors = append(s.compactors, []float64{})
+	s.maxSize = 0
+	for h := range s.compactors {
+		s.maxSize += s.capacity(h)
+	}
+}
+
+func (s *kllSketch) add(x float64) {
+	s.compactors[0] = append(s.compactors[0], x)
+	s.size++
+	if s.size >= s.maxSize {
+		s.compress()
+	}
+}
+
+func (s *kllSketch) compress() {
+	for h := 0; h < len(s.compactors); h++ {
+		if len(s.compactors[h]) >= s.capacity(h) {
+			if h+1 >= len(s.compactors) {
+				s.grow()
+			}
+			items := s.compactors[h]
+			sort.Float64s(items)
+			for i := s.rand.Intn(2); i < len(items); i += 2 {
+				s.compactors[h+1] = append(s.compactors[h+1], items[i])
+			}
+			s.compactors[h] = items[:0]
+			break
+		}
+	}
+	s.size = 0
+	for _, c := range s.compactors {
+		s.size += len(c)
+	}
+}
+
+// Answer the approximate q-quantile, for 0 <= q <= 1, of the numbers added to the sketch.
+func (s *kllSketch) quantile(q float64) float64 {
+	type weighted struct {
+		value  float64
+		weight uint64
+	}
+	items := []weighted{}
+	total := uint64(0)
+	for h, c := range s.compactors {
+		for _, x := range c {
+			items = append(items, weighted{x, 1 << uint(h)})
+			total += 1 << uint(h)
+		}
+	}
+	if len(items) == 0 {
+		return math.NaN()
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].value < items[j].value
+	})
+	target := q * float64(total)
+	cumulative := uint64(0)
+	for _, it := range items {
+		cumulative += it.weight
+		if float64(cumulative) >= target {
+			return it.value
+		}
+	}
+	return items[len(items)-1].value
+}
+
+// A frequentValue is a value and its (possibly over-estimated) count.
+type frequentValue struct {
+	value string
+	count int
+	error int // the greatest amount by which count may exceed the true count
+	index int
+}
+
+// A spaceSaving sketch tracks the most frequent of a stream of strings using a bounded number of
+// counters (per Metwally, Agrawal and El Abbadi). When a value that is not tracked arrives and every
+// counter is in use, the counter with the least count is reassigned to the new value, inheriting its
+// count as the error. Any value whose true count exceeds n/capacity is guaranteed to be tracked.
+type spaceSaving struct {
+	capacity int
+	values   map[string]*frequentValue
+	heap     []*frequentValue // a min-heap by count
+}
+
+func newSpaceSaving(capacity int) *spaceSaving {
+	return &spaceSaving{capacity: capacity, values: map[string]*frequentValue{}}
+}
+
+func (s *spaceSaving) Len() int           { return len(s.heap) }
+func (s *spaceSaving) Less(i, j int) bool { return s.heap[i].count < s.heap[j].count }
+func (s *spaceSaving) Swap(i, j int) {
+	s.heap[i], s.heap[j] = s.heap[j], s.heap[i]
+	s.heap[i].index = i
+	s.heap[j].index = j
+}
+func (s *spaceSaving) Push(x interface{}) {
+	v := x.(*frequentValue)
+	v.index = len(s.heap)
+	s.heap = append(s.heap, v)
+}
+func (s *spaceSaving) Pop() interface{} {
+	n := len(s.heap)
+	v := s.heap[n-1]
+	s.heap = s.heap[:n-1]
+	return v
+}
+
+func (s *spaceSaving) add(value string) {
+	if v, ok := s.values[value]; ok {
+		v.count++
+		heap.Fix(s, v.index)
+	} else if len(s.heap) < s.capacity {
+		v := &frequentValue{value: value, count: 1}
+		s.values[value] = v
+		heap.Push(s, v)
+	} else {
+		v := s.heap[0]
+		delete(s.values, v.value)
+		v.value = value
+		v.error = v.count
+		v.count++
+		s.values[value] = v
+		heap.Fix(s, 0)
+	}
+}
+
+// Answer the tracked values in order of decreasing count.
+func (s *spaceSaving) top() []frequentValue {
+	result := make([]frequentValue, len(s.heap))
+	for i, v := range s.heap {
+		result[i] = *v
+	}
+	sortFrequentValues(result)
+	return result
+}
+
+// Sort values in order of decreasing count and then increasing value.
+func sortFrequentValues(values []frequentValue) {
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].count != values[j].count {
+			return values[i].count > values[j].count
+		}
+		return values[i].value < values[j].value
+	})
+}
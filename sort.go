@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/wildducktheories/go-csv/utils"
 	"sort"
+	"time"
 )
 
 // An adapter that converts a slice of CSV records into an instance of sort.Interface using the
@@ -75,11 +76,67 @@ func LessNumericStrings(l, r string) bool {
 	}
 }
 
+// A NonNumericOrder determines how the values of a numeric or decimal sort key that are not numbers are ordered.
+type NonNumericOrder int
+
+const (
+	NonNumericLexical NonNumericOrder = iota // compared lexically with the other values
+	NonNumericError                          // reported as an error by the SortProcess
+	NonNumericFirst                          // ordered before the numbers
+	NonNumericLast                           // ordered after the numbers
+)
+
+// Answer the name of the specified order.
+func (o NonNumericOrder) String() string {
+	switch o {
+	case NonNumericError:
+		return "error"
+	case NonNumericFirst:
+		return "first"
+	case NonNumericLast:
+		return "last"
+	default:
+		return "lexical"
+	}
+}
+
+// Answer the order with the specified name.
+func ParseNonNumericOrder(s string) (NonNumericOrder, error) {
+	for _, o := range []NonNumericOrder{NonNumericLexical, NonNumericError, NonNumericFirst, NonNumericLast} {
+		if o.String() == s {
+			return o, nil
+		}
+	}
+	return NonNumericLexical, fmt.Errorf("unknown non-numeric order: %s (expected one of: lexical, error, first, last)", s)
+}
+
 // Specifies the keys to be used by a CSV sort.
 type SortKeys struct {
 	Keys     []string // list of columns to use for sorting
 	Numeric  []string // list of columns for which a numerical string comparison is used
+	Decimal  []string // list of columns for which an exact decimal string comparison is used
 	Reversed []string // list of columns for which the comparison is reversed
+
+	// Dates maps columns to the layouts (per TimestampFormat) of the timestamps they contain. The
+	// values of these columns are compared as instants, interpreted in Location (UTC if nil).
+	Dates    map[string]string
+	Location *time.Location
+
+	// Comparators maps columns to the comparators used to compare their values, in preference
+	// to the comparators selected by the other fields. See also LookupStringComparator.
+	Comparators map[string]StringComparator
+
+	// The values of the columns listed in NullsFirst or NullsLast that are empty or equal to one of
+	// NullTokens are NULL. NULLs are ordered before (or, for NullsLast, after) all other values,
+	// irrespective of Reversed.
+	NullTokens []string
+	NullsFirst []string
+	NullsLast  []string
+
+	// NonNumeric determines how the values of Numeric and Decimal columns that are not numbers,
+	// including NULLs that are not otherwise ordered, are ordered. Like NULLs, values ordered first
+	// or last are ordered irrespective of Reversed.
+	NonNumeric NonNumericOrder
 }
 
 // Answer a Sort for the specified slice of CSV records, using the comparators derived from the
@@ -110,6 +167,87 @@ func (p *SortKeys) AsSortProcess() *SortProcess {
 	return &SortProcess{
 		AsSort: p.AsSort,
 		Keys:   p.Keys,
+		Check:  p.check(),
+	}
+}
+
+// Answer a function that answers true if a value of the specified key is a number or nil
+// if the key is neither a numeric nor a decimal key.
+func (p *SortKeys) numberTest(k string) func(v string) bool {
+	if _, ok := p.Comparators[k]; ok {
+		return nil
+	} else if _, ok := p.Dates[k]; ok {
+		return nil
+	} else if utils.NewIndex(p.Decimal).Contains(k) {
+		return func(v string) bool {
+			_, err := ParseDecimal(v)
+			return err == nil
+		}
+	} else if utils.NewIndex(p.Numeric).Contains(k) {
+		return func(v string) bool {
+			var f float64
+			_, err := fmt.Sscanf(v, "%f", &f)
+			return err == nil
+		}
+	}
+	return nil
+}
+
+// Answer a function that answers true if a value of the specified key is NULL or nil if
+// the NULLs of the key are not ordered.
+func (p *SortKeys) nullTest(k string) func(v string) bool {
+	if !utils.NewIndex(p.NullsFirst).Contains(k) && !utils.NewIndex(p.NullsLast).Contains(k) {
+		return nil
+	}
+	tokens := utils.NewIndex(p.NullTokens)
+	return func(v string) bool {
+		return v == "" || tokens.Contains(v)
+	}
+}
+
+// Answer a function that reports the first value of a numeric or decimal key of a record that is
+// neither a number nor NULL, or nil if the receiver does not require such values to be reported.
+func (p *SortKeys) check() func(r Record) error {
+	if p.NonNumeric != NonNumericError {
+		return nil
+	}
+	keys := []string{}
+	isNumber := map[string]func(string) bool{}
+	isNull := map[string]func(string) bool{}
+	for _, k := range p.Keys {
+		if f := p.numberTest(k); f != nil {
+			keys = append(keys, k)
+			isNumber[k] = f
+			isNull[k] = p.nullTest(k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return func(r Record) error {
+		for _, k := range keys {
+			v := r.Get(k)
+			if isNumber[k](v) || (isNull[k] != nil && isNull[k](v)) {
+				continue
+			}
+			return fmt.Errorf("%s is not a number: %s", k, v)
+		}
+		return nil
+	}
+}
+
+// Answer a comparator that orders the values for which test answers true before (or, if last
+// is true, after) the other values and otherwise compares values with less.
+func orderFirst(less StringComparator, test func(string) bool, last bool) StringComparator {
+	return func(l, r string) bool {
+		lt, rt := test(l), test(r)
+		if lt == rt {
+			return less(l, r)
+		} else if last {
+			return rt
+		} else {
+			return lt
+		}
 	}
 }
 
@@ -124,13 +262,24 @@ func (p *SortKeys) AsStringProjection() StringProjection {
 	}
 }
 
-// Answers a comparator that can compare two slices.
-func (p *SortKeys) AsStringSliceComparator() StringSliceComparator {
+// Answers a slice of string comparators, one for each key.
+func (p *SortKeys) asStringComparators() []StringComparator {
 	numeric := utils.NewIndex(p.Numeric)
+	decimal := utils.NewIndex(p.Decimal)
 	reverseIndex := utils.NewIndex(p.Reversed)
+	location := p.Location
+	if location == nil {
+		location = time.UTC
+	}
 	comparators := make([]StringComparator, len(p.Keys))
 	for i, k := range p.Keys {
-		if numeric.Contains(k) {
+		if less, ok := p.Comparators[k]; ok {
+			comparators[i] = less
+		} else if layout, ok := p.Dates[k]; ok {
+			comparators[i] = LessTimestampStrings(&TimestampFormat{Layout: layout, Location: location})
+		} else if decimal.Contains(k) {
+			comparators[i] = LessDecimalStrings
+		} else if numeric.Contains(k) {
 			comparators[i] = LessNumericStrings
 		} else {
 			comparators[i] = LessStrings
@@ -138,34 +287,34 @@ func (p *SortKeys) AsStringSliceComparator() StringSliceComparator {
 		if reverseIndex.Contains(k) {
 			f := comparators[i]
 			comparators[i] = func(l, r string) bool {
-				return !f(l, r)
+				return f(r, l)
 			}
 		}
+		if isNumber := p.numberTest(k); isNumber != nil && (p.NonNumeric == NonNumericFirst || p.NonNumeric == NonNumericLast) {
+			isNotNumber := func(v string) bool { return !isNumber(v) }
+			comparators[i] = orderFirst(comparators[i], isNotNumber, p.NonNumeric == NonNumericLast)
+		}
+		if isNull := p.nullTest(k); isNull != nil {
+			last := utils.NewIndex(p.NullsLast).Contains(k)
+			comparators[i] = orderFirst(comparators[i], isNull, last)
+		}
 	}
-	return AsStringSliceComparator(comparators)
+	return comparators
+}
+
+// Answers a comparator that can compare two slices.
+func (p *SortKeys) AsStringSliceComparator() StringSliceComparator {
+	return AsStringSliceComparator(p.asStringComparators())
 }
 
 // Answers a slice of comparators that can compare two records.
 func (p *SortKeys) AsRecordComparators() []RecordComparator {
-	numeric := utils.NewIndex(p.Numeric)
-	reverseIndex := utils.NewIndex(p.Reversed)
 	comparators := make([]RecordComparator, len(p.Keys))
-	for i, k := range p.Keys {
-		k := k
-		if numeric.Contains(k) {
-			comparators[i] = func(l, r Record) bool {
-				return LessNumericStrings(l.Get(k), r.Get(k))
-			}
-		} else {
-			comparators[i] = func(l, r Record) bool {
-				return LessStrings(l.Get(k), r.Get(k))
-			}
-		}
-		if reverseIndex.Contains(k) {
-			f := comparators[i]
-			comparators[i] = func(l, r Record) bool {
-				return !f(l, r)
-			}
+	for i, less := range p.asStringComparators() {
+		k := p.Keys[i]
+		less := less
+		comparators[i] = func(l, r Record) bool {
+			return less(l.Get(k), r.Get(k))
 		}
 	}
 	return comparators
@@ -182,6 +331,7 @@ func (p *SortKeys) AsRecordComparator() RecordComparator {
 type SortProcess struct {
 	AsSort func(data []Record) sort.Interface
 	Keys   []string
+	Check  func(r Record) error // if specified, the sort fails with the first error answered for a record
 }
 
 // Run the sort process specified by the receiver against the specified CSV reader,
@@ -211,6 +361,14 @@ func (p *SortProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- err
 			return err
 		} else {
 
+			if p.Check != nil {
+				for _, e := range all {
+					if err := p.Check(e); err != nil {
+						return err
+					}
+				}
+			}
+
 			sort.Sort(p.AsSort(all))
 
 			for _, e := range all {
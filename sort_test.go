@@ -0,0 +1,59 @@
+package csv
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Sort the specified values of column v with the specified keys and answer them joined by commas.
+func sortValues(keys *SortKeys, values ...string) string {
+	builder := NewRecordBuilder([]string{"v"})
+	data := make([]Record, len(values))
+	for i, v := range values {
+		data[i] = builder([]string{v})
+	}
+	sort.Stable(keys.AsSort(data))
+	result := make([]string, len(data))
+	for i, r := range data {
+		result[i] = r.Get("v")
+	}
+	return strings.Join(result, ",")
+}
+
+func TestSortNulls(t *testing.T) {
+	values := []string{"10", "", "NA", "2", "abc", "-1"}
+	cases := []struct {
+		keys     SortKeys
+		expected string
+	}{
+		{SortKeys{Numeric: []string{"v"}, NullTokens: []string{"NA"}, NullsFirst: []string{"v"}}, ",NA,-1,2,10,abc"},
+		{SortKeys{Numeric: []string{"v"}, NullTokens: []string{"NA"}, NullsLast: []string{"v"}}, "-1,2,10,abc,,NA"},
+		{SortKeys{Numeric: []string{"v"}, Reversed: []string{"v"}, NullTokens: []string{"NA"}, NullsLast: []string{"v"}}, "abc,10,2,-1,NA,"},
+		{SortKeys{Numeric: []string{"v"}, Reversed: []string{"v"}, NullTokens: []string{"NA"}, NullsFirst: []string{"v"}}, "NA,,abc,10,2,-1"},
+		{SortKeys{Numeric: []string{"v"}, Reversed: []string{"v"}, NullTokens: []string{"NA"}, NullsLast: []string{"v"}, NonNumeric: NonNumericFirst}, "abc,10,2,-1,NA,"},
+		{SortKeys{Numeric: []string{"v"}, Reversed: []string{"v"}, NullTokens: []string{"NA"}, NullsFirst: []string{"v"}, NonNumeric: NonNumericLast}, "NA,,10,2,-1,abc"},
+		{SortKeys{Decimal: []string{"v"}, NullsLast: []string{"v"}, NonNumeric: NonNumericFirst}, "NA,abc,-1,2,10,"},
+	}
+	for _, c := range cases {
+		keys := c.keys
+		keys.Keys = []string{"v"}
+		if got := sortValues(&keys, values...); got != c.expected {
+			t.Fatalf("%+v: got %q, expected %q", c.keys, got, c.expected)
+		}
+	}
+}
+
+func TestSortNonNumericError(t *testing.T) {
+	keys := &SortKeys{Keys: []string{"v"}, Numeric: []string{"v"}, NullsFirst: []string{"v"}, NonNumeric: NonNumericError}
+	check := keys.AsSortProcess().Check
+	builder := NewRecordBuilder([]string{"v"})
+	for _, v := range []string{"1", "-2.5", ""} {
+		if err := check(builder([]string{v})); err != nil {
+			t.Fatalf("%q: %v", v, err)
+		}
+	}
+	if err := check(builder([]string{"abc"})); err == nil {
+		t.Fatalf("expected an error for a non-numeric value")
+	}
+}
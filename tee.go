@@ -0,0 +1,346 @@
+package csv
+
+import (
+	encoding "encoding/csv"
+	"os"
+	"sync"
+)
+
+// A TeePolicy determines how a Tee behaves when one of its readers consumes records more
+// slowly than the others.
+type TeePolicy int
+
+const (
+	// BlockOnSlowest delivers each record to every reader before the next record is read from
+	// the source, so the tee advances at the pace of its slowest reader.
+	BlockOnSlowest TeePolicy = iota
+	// SpillToDisk buffers the records of slow readers, first in memory and then in a temporary
+	// file, so that no reader is held up by another.
+	SpillToDisk
+)
+
+// The number of records buffered in memory for each reader of a SpillToDisk Tee
+// if the MemoryLimit is not specified.
+const DefaultTeeMemoryLimit = 1024
+
+// A Tee duplicates the stream of a single Reader into several Readers, each of which yields
+// a copy of every record of the source stream.
+type Tee struct {
+	Policy      TeePolicy // the policy used to handle slow readers
+	MemoryLimit int       // the number of records buffered in memory for each reader before spilling to disk (SpillToDisk only)
+	TempDir     string    // the directory used for spill files. The default temporary directory is used if empty.
+}
+
+type teeReader struct {
+	header []string
+	init   chan interface{}
+	ch     chan Record
+	quit   chan interface{}
+	once   sync.Once
+	err    error
+}
+
+// Answer n Readers each of which yields a copy of every record of the specified reader. The
+// returned readers share a Tee which blocks on its slowest reader.
+func NewTee(r Reader, n int) []Reader {
+	return (&Tee{}).Split(r, n)
+}
+
+// Answer n Readers each of which yields a copy of every record of the specified reader. The
+// source reader is consumed by the Tee and is closed when it is exhausted or when all of the
+// returned readers have been closed.
+func (t *Tee) Split(r Reader, n int) []Reader {
+	outputs := make([]*teeReader, n)
+	result := make([]Reader, n)
+	for i := range outputs {
+		outputs[i] = &teeReader{
+			init: make(chan interface{}),
+			ch:   make(chan Record),
+			quit: make(chan interface{}),
+		}
+		result[i] = outputs[i]
+	}
+
+	go func() {
+		defer r.Close()
+
+		header := r.Header()
+		for _, o := range outputs {
+			o.header = header
+			close(o.init)
+		}
+
+		if t.Policy == SpillToDisk {
+			t.spill(r, outputs)
+		} else {
+			t.block(r, outputs)
+		}
+	}()
+
+	return result
+}
+
+// Deliver each record to every open output before reading the next record.
+func (t *Tee) block(r Reader, outputs []*teeReader) {
+	builder := NewRecordBuilder(r.Header())
+	for rec := range r.C() {
+		open := 0
+		for _, o := range outputs {
+			select {
+			case o.ch <- builder(rec.AsSlice()):
+				open++
+			case <-o.quit:
+			}
+		}
+		if open == 0 {
+			break
+		}
+	}
+	err := r.Error()
+	for _, o := range outputs {
+		o.err = err
+		close(o.ch)
+	}
+}
+
+// Buffer the records of each output in a spool so that the source is never blocked
+// by a slow output.
+func (t *Tee) spill(r Reader, outputs []*teeReader) {
+	limit := t.MemoryLimit
+	if limit <= 0 {
+		limit = DefaultTeeMemoryLimit
+	}
+
+	header := r.Header()
+	builder := NewRecordBuilder(header)
+	spools := make([]*spool, len(outputs))
+	var wg sync.WaitGroup
+	for i, o := range outputs {
+		s := &spool{
+			limit:   limit,
+			dir:     t.TempDir,
+			builder: builder,
+		}
+		s.cond = sync.NewCond(&s.mu)
+		spools[i] = s
+		wg.Add(1)
+		go func(o *teeReader) {
+			defer wg.Done()
+			o.drain(s)
+		}(o)
+	}
+
+	for rec := range r.C() {
+		open := 0
+		for _, s := range spools {
+			if s.push(builder(rec.AsSlice())) {
+				open++
+			}
+		}
+		if open == 0 {
+			break
+		}
+	}
+
+	err := r.Error()
+	for _, s := range spools {
+		s.finish(err)
+	}
+	wg.Wait()
+}
+
+// Copy records from the spool to the output channel until the spool is exhausted
+// or the output is closed. The spill file is removed before the output channel is closed.
+func (o *teeReader) drain(s *spool) {
+	for {
+		rec, ok := s.pop()
+		if !ok {
+			break
+		}
+		select {
+		case o.ch <- rec:
+			continue
+		case <-o.quit:
+			s.abandon()
+		}
+		break
+	}
+	o.err = s.error()
+	s.release()
+	close(o.ch)
+}
+
+func (o *teeReader) Header() []string {
+	<-o.init
+	return o.header
+}
+
+func (o *teeReader) C() <-chan Record {
+	return o.ch
+}
+
+func (o *teeReader) Error() error {
+	<-o.init
+	return o.err
+}
+
+func (o *teeReader) Close() {
+	o.once.Do(func() {
+		close(o.quit)
+	})
+}
+
+// A spool is an unbounded FIFO queue of records which holds up to limit records
+// in memory and spills the remainder into a temporary file.
+type spool struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     int
+	dir       string
+	builder   RecordBuilder
+	memory    []Record
+	file      *os.File
+	encoder   *encoding.Writer
+	decoder   *encoding.Reader
+	input     *os.File
+	spilled   int
+	done      bool
+	abandoned bool
+	err       error
+}
+
+// Append a record to the spool. Answers false if the consumer of the spool has gone away.
+func (s *spool) push(r Record) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.abandoned || s.err != nil {
+		return !s.abandoned
+	}
+
+	// records are only held in memory if no earlier record is waiting in the file
+	if s.spilled == 0 && len(s.memory) < s.limit {
+		s.memory = append(s.memory, r)
+		s.cond.Signal()
+		return true
+	}
+
+	if s.file == nil {
+		if s.file, s.err = os.CreateTemp(s.dir, "csv-tee-"); s.err != nil {
+			s.cond.Signal()
+			return true
+		}
+		if s.input, s.err = os.Open(s.file.Name()); s.err != nil {
+			s.cond.Signal()
+			return true
+		}
+		s.encoder = encoding.NewWriter(s.file)
+		s.decoder = encoding.NewReader(s.input)
+		s.decoder.FieldsPerRecord = -1
+	}
+
+	if s.err = s.encoder.Write(r.AsSlice()); s.err == nil {
+		s.spilled++
+	}
+	s.cond.Signal()
+	return true
+}
+
+// Answer the next record from the spool, blocking until one is available. Answers false
+// if the spool has been exhausted.
+func (s *spool) pop() (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.memory) == 0 && s.spilled == 0 && !s.done && s.err == nil {
+		s.cond.Wait()
+	}
+
+	if len(s.memory) > 0 {
+		r := s.memory[0]
+		s.memory[0] = nil
+		s.memory = s.memory[1:]
+		return r, true
+	}
+
+	if s.spilled > 0 && s.err == nil {
+		s.encoder.Flush()
+		if s.err = s.encoder.Error(); s.err != nil {
+			return nil, false
+		}
+		var fields []string
+		if fields, s.err = s.decoder.Read(); s.err != nil {
+			return nil, false
+		}
+		s.spilled--
+		return s.builder(fields), true
+	}
+
+	return nil, false
+}
+
+// Mark the spool as complete, recording the error, if any, that terminated the source stream.
+func (s *spool) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	if s.err == nil {
+		s.err = err
+	}
+	s.cond.Signal()
+}
+
+// Discard the contents of the spool because its consumer has gone away.
+func (s *spool) abandon() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.abandoned = true
+	s.memory = nil
+	s.spilled = 0
+}
+
+func (s *spool) error() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Release the spill file, if any.
+func (s *spool) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file != nil {
+		s.input.Close()
+		s.file.Close()
+		os.Remove(s.file.Name())
+	}
+}
+
+// A process that copies the reader to the writer and, at the same time, copies each record
+// into a second writer constructed by Builder. This allows a pipeline constructed with
+// NewPipeLine to branch, for example to capture an intermediate stream to a file.
+type TeeProcess struct {
+	Builder WriterBuilder
+}
+
+func (p *TeeProcess) Run(r Reader, b WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer r.Close()
+
+		w := b(r.Header())
+		defer w.Close(err)
+
+		t := p.Builder(r.Header())
+		defer t.Close(err)
+
+		for rec := range r.C() {
+			if e := w.Write(rec); e != nil {
+				return e
+			}
+			if e := t.Write(rec); e != nil {
+				return e
+			}
+		}
+		return r.Error()
+	}()
+}
@@ -0,0 +1,150 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Answer a reader of n records whose v column counts from 0.
+func countingReader(n int) Reader {
+	data := []string{"v"}
+	for i := 0; i < n; i++ {
+		data = append(data, fmt.Sprint(i))
+	}
+	return stringReader(strings.Join(data, "\n") + "\n")
+}
+
+// Answer the next record of the specified reader, or nil if none arrives within the timeout.
+func receive(r Reader, timeout time.Duration) Record {
+	select {
+	case rec := <-r.C():
+		return rec
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+// Answer an error unless the specified reader yields the records of a counting reader from start.
+func expectCount(r Reader, start int, n int) error {
+	i := start
+	for rec := range r.C() {
+		if v := rec.Get("v"); v != fmt.Sprint(i) {
+			return fmt.Errorf("record %d: found %s", i, v)
+		}
+		i++
+	}
+	if i != n {
+		return fmt.Errorf("expected %d records, found %d", n, i)
+	}
+	return r.Error()
+}
+
+// Answer the names of the spill files in the specified directory.
+func spillFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "csv-tee-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestTeeBlocksOnSlowest(t *testing.T) {
+	readers := (&Tee{Policy: BlockOnSlowest}).Split(countingReader(3), 2)
+	slow, fast := readers[0], readers[1]
+
+	if rec := receive(fast, 50*time.Millisecond); rec != nil {
+		t.Fatalf("expected the fast reader to wait for the slow reader, received %s", rec.Get("v"))
+	}
+	if rec := receive(slow, time.Second); rec == nil || rec.Get("v") != "0" {
+		t.Fatalf("expected record 0 from the slow reader")
+	}
+	if rec := receive(fast, time.Second); rec == nil || rec.Get("v") != "0" {
+		t.Fatalf("expected record 0 from the fast reader")
+	}
+	if rec := receive(fast, 50*time.Millisecond); rec != nil {
+		t.Fatalf("expected the fast reader to wait for the slow reader, received %s", rec.Get("v"))
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- expectCount(slow, 1, 3)
+	}()
+	if err := expectCount(fast, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTeeSpillsToDisk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	n := 100
+	readers := (&Tee{Policy: SpillToDisk, MemoryLimit: 2, TempDir: dir}).Split(countingReader(n), 2)
+	fast, slow := readers[0], readers[1]
+
+	// the fast reader is not held up by the slow reader, whose records are spilled
+	if err := expectCount(fast, 0, n); err != nil {
+		t.Fatal(err)
+	}
+	if files := spillFiles(t, dir); len(files) != 1 {
+		t.Fatalf("expected 1 spill file, found %d", len(files))
+	}
+
+	if err := expectCount(slow, 0, n); err != nil {
+		t.Fatal(err)
+	}
+	if files := spillFiles(t, dir); len(files) != 0 {
+		t.Fatalf("expected the spill file to be removed, found %v", files)
+	}
+}
+
+func TestTeeRemovesSpillFileOnClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	n := 100
+	readers := (&Tee{Policy: SpillToDisk, MemoryLimit: 2, TempDir: dir}).Split(countingReader(n), 2)
+	fast, slow := readers[0], readers[1]
+
+	if err := expectCount(fast, 0, n); err != nil {
+		t.Fatal(err)
+	}
+	if rec := receive(slow, time.Second); rec == nil || rec.Get("v") != "0" {
+		t.Fatalf("expected record 0 from the slow reader")
+	}
+	slow.Close()
+	for range slow.C() {
+	}
+	if files := spillFiles(t, dir); len(files) != 0 {
+		t.Fatalf("expected the spill file to be removed, found %v", files)
+	}
+}
+
+func TestTeeProcess(t *testing.T) {
+	var main, branch bytes.Buffer
+	p := &TeeProcess{Builder: WithIoWriter(bufferCloser{&branch})}
+	errCh := make(chan error, 1)
+	p.Run(countingReader(3), WithIoWriter(bufferCloser{&main}), errCh)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	expected := "v\n0\n1\n2\n"
+	if main.String() != expected || branch.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s\nand:\n%s", expected, main.String(), branch.String())
+	}
+}
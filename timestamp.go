@@ -0,0 +1,71 @@
+package csv
+
+import (
+	"strconv"
+	"time"
+)
+
+// A TimestampFormat parses and formats the values of a timestamp column. Layout is either one of
+// s, ms or ns, in which case the timestamp is an integer number of seconds, milliseconds or
+// nanoseconds since the Unix epoch, or a go time layout (per time.Parse) that is interpreted in
+// the specified Location.
+type TimestampFormat struct {
+	Layout   string         // s, ms, ns or a go time layout
+	Location *time.Location // the location in which a timestamp without a zone is interpreted
+}
+
+// Answer a TimestampFormat for the specified layout and the location named per time.LoadLocation.
+func NewTimestampFormat(layout string, location string) (*TimestampFormat, error) {
+	if loc, err := time.LoadLocation(location); err != nil {
+		return nil, err
+	} else {
+		return &TimestampFormat{Layout: layout, Location: loc}, nil
+	}
+}
+
+// Parse the specified timestamp.
+func (f *TimestampFormat) Parse(s string) (time.Time, error) {
+	switch f.Layout {
+	case "ns", "s", "ms":
+		if n, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return time.Unix(0, 0), err
+		} else if f.Layout == "ns" {
+			return time.Unix(0, n), nil
+		} else if f.Layout == "s" {
+			return time.Unix(n, 0), nil
+		} else {
+			return time.Unix(0, n*1000000), nil
+		}
+	default:
+		return time.ParseInLocation(f.Layout, s, f.Location)
+	}
+}
+
+// Answer a comparator that answers true if l is less than r according to a comparison of the
+// instants represented by l and r (if l and r are both parseable as timestamps of the specified
+// format) or according to a lexical comparison otherwise.
+func LessTimestampStrings(format *TimestampFormat) StringComparator {
+	return func(l, r string) bool {
+		if lt, err := format.Parse(l); err != nil {
+			return LessStrings(l, r)
+		} else if rt, err := format.Parse(r); err != nil {
+			return LessStrings(l, r)
+		} else {
+			return lt.Before(rt)
+		}
+	}
+}
+
+// Format the specified time.
+func (f *TimestampFormat) Format(t time.Time) string {
+	switch f.Layout {
+	case "ns":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	case "s":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "ms":
+		return strconv.FormatInt(t.UnixNano()/1000000, 10)
+	default:
+		return t.In(f.Location).Format(f.Layout)
+	}
+}
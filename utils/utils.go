@@ -47,3 +47,13 @@ func Intersect(a []string, b []string) ([]string, []string, []string) {
 	aNotB = aNotB[0:i]
 	return result, aNotB, bNotA
 }
+
+// Calculate the union of two string slices. The result contains the elements of the first
+// slice, in order, followed by the elements of the second slice that do not occur in the first.
+func Union(a []string, b []string) []string {
+	_, _, bNotA := Intersect(a, b)
+	result := make([]string, len(a)+len(bNotA))
+	copy(result, a)
+	copy(result[len(a):], bNotA)
+	return result
+}
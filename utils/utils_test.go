@@ -14,3 +14,10 @@ func TestIntersect(t *testing.T) {
 		t.Fatalf("bNotA %v", bNotA)
 	}
 }
+
+func TestUnion(t *testing.T) {
+	union := Union([]string{"a", "b"}, []string{"c", "b", "d"})
+	if len(union) != 4 || union[0] != "a" || union[1] != "b" || union[2] != "c" || union[3] != "d" {
+		t.Fatalf("union %v", union)
+	}
+}
@@ -0,0 +1,234 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/wildducktheories/go-csv/utils"
+)
+
+// A WindowFunction specifies an additional column that is computed by a WindowProcess. Function is
+// one of:
+//
+//	row_number - the position of the record within its partition, starting at 1
+//	rank - the row_number of the first record of the partition with the same order keys
+//	dense_rank - the number of distinct values of the order keys up to and including the record
+//	lag - the value of Argument in the record N records before the record (N defaults to 1)
+//	lead - the value of Argument in the record N records after the record (N defaults to 1)
+//	cumsum - the sum of the values of Argument up to and including the record
+//	cummin - the least value of Argument up to and including the record
+//	cummax - the greatest value of Argument up to and including the record
+//	moving_avg - the mean of the values of Argument in the last N records up to and including the record
+//
+// lag and lead generate an empty value if there is no such record in the partition. The cumulative
+// functions and moving_avg ignore empty values.
+type WindowFunction struct {
+	Column   string // the name of the additional column
+	Function string // the name of the function
+	Argument string // the input column used by lag, lead, the cumulative functions and moving_avg
+	N        int    // the offset of lag and lead or the number of records averaged by moving_avg
+}
+
+// The names of the functions supported by WindowFunction.
+var WindowFunctionNames = []string{"row_number", "rank", "dense_rank", "lag", "lead", "cumsum", "cummin", "cummax", "moving_avg"}
+
+// Given a header-prefixed input stream of CSV records that is sorted by the partition keys (PartitionKeys)
+// and then by the order keys (Order), WindowProcess appends one column to each record for each of the
+// specified Functions. Each function is evaluated over the records of the partition that contains the
+// record, in the order of the input stream. The partition keys are compared in the same way as the keys
+// of Order, so a partition key listed in Order.Numeric, for example, is compared numerically.
+//
+// The records of one partition are held in memory at a time. It is an error for the records of a
+// partition to be out of order or for a partition to follow one whose keys are greater, so the records
+// of a partition cannot be separated by those of another.
+type WindowProcess struct {
+	PartitionKeys []string         // the columns that identify each partition
+	Order         SortKeys         // the keys by which the records of each partition are sorted
+	Functions     []WindowFunction // the functions to evaluate
+}
+
+// Answer an error if the functions of the receiver are not valid for the specified header.
+func (p *WindowProcess) check(header []string) error {
+	index := utils.NewIndex(header)
+	known := utils.NewIndex(WindowFunctionNames)
+	columns := map[string]bool{}
+	for _, f := range p.Functions {
+		if !known.Contains(f.Function) {
+			return fmt.Errorf("unknown window function: %s", f.Function)
+		}
+		if index.Contains(f.Column) || columns[f.Column] {
+			return fmt.Errorf("%s already exists in data header", f.Column)
+		}
+		columns[f.Column] = true
+		switch f.Function {
+		case "row_number", "rank", "dense_rank":
+		default:
+			if !index.Contains(f.Argument) {
+				return fmt.Errorf("%s does not exist in the data header", f.Argument)
+			}
+		}
+		if f.N < 0 || (f.Function == "moving_avg" && f.N < 1) {
+			return fmt.Errorf("%s: invalid number of records: %d", f.Column, f.N)
+		}
+	}
+	return nil
+}
+
+// Answer the values of the specified function for each record of a partition.
+func (p *WindowProcess) evaluate(f WindowFunction, partition []Record, less RecordComparator) ([]string, error) {
+	result := make([]string, len(partition))
+	n := f.N
+	if n == 0 {
+		n = 1
+	}
+
+	switch f.Function {
+	case "row_number":
+		for i := range partition {
+			result[i] = strconv.Itoa(i + 1)
+		}
+	case "rank", "dense_rank":
+		rank, dense := 0, 0
+		for i, r := range partition {
+			if i == 0 || less(partition[i-1], r) || less(r, partition[i-1]) {
+				rank = i + 1
+				dense++
+			}
+			if f.Function == "rank" {
+				result[i] = strconv.Itoa(rank)
+			} else {
+				result[i] = strconv.Itoa(dense)
+			}
+		}
+	case "lag":
+		for i := n; i < len(partition); i++ {
+			result[i] = partition[i-n].Get(f.Argument)
+		}
+	case "lead":
+		for i := 0; i+n < len(partition); i++ {
+			result[i] = partition[i+n].Get(f.Argument)
+		}
+	case "cumsum":
+		agg := &sumAggregator{}
+		for i, r := range partition {
+			if err := agg.Add(r.Get(f.Argument)); err != nil {
+				return nil, fmt.Errorf("%s: %v", f.Argument, err)
+			}
+			result[i] = agg.Value()
+		}
+	case "cummin", "cummax":
+		agg := &extremeAggregator{max: f.Function == "cummax"}
+		for i, r := range partition {
+			agg.Add(r.Get(f.Argument))
+			result[i] = agg.Value()
+		}
+	case "moving_avg":
+		values := make([]float64, len(partition))
+		present := make([]bool, len(partition))
+		sum, count := 0.0, 0
+		for i, r := range partition {
+			if v := r.Get(f.Argument); v != "" {
+				var err error
+				if values[i], err = parseFloat(v); err != nil {
+					return nil, fmt.Errorf("%s: not a number: %s", f.Argument, v)
+				}
+				present[i] = true
+				sum += values[i]
+				count++
+			}
+			if j := i - n; j >= 0 && present[j] {
+				sum -= values[j]
+				count--
+			}
+			if count > 0 {
+				result[i] = formatFloat(sum / float64(count))
+			}
+		}
+	}
+	return result, nil
+}
+
+func (p *WindowProcess) Run(reader Reader, builder WriterBuilder, errCh chan<- error) {
+	errCh <- func() (err error) {
+		defer reader.Close()
+
+		dataHeader := reader.Header()
+
+		keys := append(append([]string{}, p.PartitionKeys...), p.Order.Keys...)
+		if _, x, _ := utils.Intersect(keys, dataHeader); len(x) != 0 {
+			return fmt.Errorf("%s does not exist in the data header", Format(x))
+		}
+		if err = p.check(dataHeader); err != nil {
+			return err
+		}
+
+		outputHeader := make([]string, len(dataHeader), len(dataHeader)+len(p.Functions))
+		copy(outputHeader, dataHeader)
+		for _, f := range p.Functions {
+			outputHeader = append(outputHeader, f.Column)
+		}
+
+		writer := builder(outputHeader)
+		defer writer.Close(err)
+
+		less := p.Order.AsRecordComparator()
+		values := make([]string, len(p.PartitionKeys))
+		partitionKey := func(r Record) string {
+			for i, k := range p.PartitionKeys {
+				values[i] = r.Get(k)
+			}
+			return Format(values)
+		}
+
+		flush := func(partition []Record) error {
+			columns := make([][]string, len(p.Functions))
+			for i, f := range p.Functions {
+				if columns[i], err = p.evaluate(f, partition, less); err != nil {
+					return err
+				}
+			}
+			for x, r := range partition {
+				o := writer.Blank()
+				o.PutAll(r)
+				for i, f := range p.Functions {
+					o.Put(f.Column, columns[i][x])
+				}
+				if err := writer.Write(o); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		partitionKeys := p.Order
+		partitionKeys.Keys = p.PartitionKeys
+		partitionLess := partitionKeys.AsRecordComparator()
+		var partition []Record
+		var current string
+		var previous Record
+		for data := range reader.C() {
+			key := partitionKey(data)
+			if len(partition) > 0 && key != current {
+				if err = flush(partition); err != nil {
+					return err
+				}
+				previous = partition[len(partition)-1]
+				partition = partition[:0]
+			}
+			if len(partition) == 0 {
+				if previous != nil && partitionLess(data, previous) {
+					return fmt.Errorf("records are not sorted: %s follows %s", Format(data.AsSlice()), Format(previous.AsSlice()))
+				}
+				current = key
+			} else if last := partition[len(partition)-1]; less(data, last) {
+				return fmt.Errorf("records are not sorted: %s follows %s", Format(data.AsSlice()), Format(last.AsSlice()))
+			}
+			partition = append(partition, data)
+		}
+
+		if err = reader.Error(); err != nil {
+			return err
+		}
+		return flush(partition)
+	}()
+}
@@ -0,0 +1,57 @@
+package csv
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWindowProcess(t *testing.T) {
+	functions := []WindowFunction{
+		{Column: "rank", Function: "rank"},
+		{Column: "dense_rank", Function: "dense_rank"},
+	}
+	cases := []struct {
+		order    SortKeys
+		input    string
+		expected string
+	}{
+		{
+			SortKeys{Keys: []string{"v"}, Numeric: []string{"p", "v"}},
+			"p,v\n2,1\n2,1\n2,3\n10,2\n",
+			"p,v,rank,dense_rank\n2,1,1,1\n2,1,1,1\n2,3,3,2\n10,2,1,1\n",
+		},
+		{
+			SortKeys{Keys: []string{"v"}, Numeric: []string{"v"}, Reversed: []string{"v"}},
+			"p,v\na,3\na,3\na,1\nb,2\n",
+			"p,v,rank,dense_rank\na,3,1,1\na,3,1,1\na,1,3,2\nb,2,1,1\n",
+		},
+	}
+	for _, c := range cases {
+		p := &WindowProcess{PartitionKeys: []string{"p"}, Order: c.order, Functions: functions}
+		var buf bytes.Buffer
+		errCh := make(chan error, 1)
+		p.Run(stringReader(c.input), WithIoWriter(bufferCloser{&buf}), errCh)
+		if err := <-errCh; err != nil {
+			t.Fatalf("%q: %v", c.input, err)
+		}
+		if buf.String() != c.expected {
+			t.Fatalf("%q: expected:\n%s\ngot:\n%s", c.input, c.expected, buf.String())
+		}
+	}
+}
+
+func TestWindowProcessUnsorted(t *testing.T) {
+	for _, input := range []string{"p,v\n10,1\n2,1\n", "p,v\n2,1\n10,1\n2,2\n", "p,v\n2,2\n2,1\n"} {
+		p := &WindowProcess{
+			PartitionKeys: []string{"p"},
+			Order:         SortKeys{Keys: []string{"v"}, Numeric: []string{"p", "v"}},
+			Functions:     []WindowFunction{{Column: "n", Function: "row_number"}},
+		}
+		var buf bytes.Buffer
+		errCh := make(chan error, 1)
+		p.Run(stringReader(input), WithIoWriter(bufferCloser{&buf}), errCh)
+		if err := <-errCh; err == nil {
+			t.Fatalf("%q: expected an error", input)
+		}
+	}
+}